@@ -0,0 +1,207 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"github.com/wolfbolin/bolbox/pkg/configs"
+	"github.com/wolfbolin/bolbox/pkg/errors"
+	"github.com/wolfbolin/bolbox/pkg/log"
+	"github.com/wolfbolin/bolbox/pkg/log/zap"
+	"github.com/wolfbolin/bolbox/pkg/services"
+	"github.com/wolfbolin/bolbox/pkg/signals"
+)
+
+// 进程的退出码
+const (
+	ExitOK      = 0   // 全部模块正常退出
+	ExitFailure = 1   // 模块启动失败或运行中出现异常
+	ExitConfig  = 2   // 配置解析失败
+	ExitForced  = 130 // 收到第二次退出信号或优雅退出超时，强制退出
+)
+
+// 配置结构体中用于构建日志记录器与设置运行角色的字段名，字段不存在时使用默认值
+const (
+	FieldLogLevel   = "LogLevel"   // string，日志级别，如 "INFO"，默认为 INFO，运行中变更时立即生效
+	FieldLogPath    = "LogPath"    // string，日志文件路径，为空时不向文件打印日志
+	FieldLogConsole = "LogConsole" // bool，是否打印控制台日志，默认为 true
+	FieldRoles      = "Roles"      // string，逗号分隔的运行角色，为空时启动全部模块
+)
+
+// ErrInvalidLevel 配置中的日志级别无法识别
+var ErrInvalidLevel = errors.New("Log level is invalid.")
+
+// App 进程级的启动引导，统一完成配置解析、日志初始化、模块启动、信号处理与退出码设置
+type App[T any] struct {
+	Configs *configs.Manager[T]
+	Manager *services.Manager
+
+	Setup           func(app *App[T]) error // 配置解析与日志初始化之后、模块启动之前调用，返回错误时进程以 ExitFailure 退出
+	ShutdownTimeout time.Duration           // 收到退出信号后等待模块优雅退出的最长时间，默认与管理器的 ShutdownTimeout 相同，不大于 0 时不限制
+
+	modules []services.Module
+	failed  atomic.Bool
+}
+
+// New 使用配置默认值 def 与模块列表创建启动引导
+func New[T any](def *T, modules ...services.Module) *App[T] {
+	manager := services.NewManager()
+	return &App[T]{
+		Configs:         configs.NewManager(def),
+		Manager:         manager,
+		ShutdownTimeout: manager.Options.ShutdownTimeout,
+		modules:         modules,
+	}
+}
+
+// Run 创建启动引导并运行直至收到退出信号，随后以对应的退出码结束进程
+func Run[T any](def *T, modules ...services.Module) {
+	os.Exit(New(def, modules...).Run())
+}
+
+// Run 解析配置、初始化日志并启动全部模块，直至收到退出信号后优雅退出，返回进程的退出码。
+// 收到第一次退出信号时按依赖逆序通知模块退出，再次收到信号时强制退出。
+func (a *App[T]) Run() int {
+	ctx, force := signals.GracefulShutdownContext()
+	return a.serve(ctx, force)
+}
+
+// serve 运行全部模块直至 ctx 结束，force 收到信号时放弃等待模块退出
+func (a *App[T]) serve(ctx context.Context, force <-chan struct{}) int {
+	if _, err := a.Configs.Parse(); err != nil {
+		fmt.Fprintf(os.Stderr, "Parse configs failed. %+v\n", err)
+		return ExitConfig
+	}
+	if err := a.initLogger(); err != nil {
+		fmt.Fprintf(os.Stderr, "Init logger failed. %+v\n", err)
+		return ExitConfig
+	}
+	defer a.closeLogger()
+
+	if roles, ok := field[string](a.Configs.Raws(), FieldRoles); ok {
+		a.Manager.SetRoles(services.ParseRoles(roles)...)
+	}
+	for _, module := range a.modules {
+		if err := a.Manager.AddModule(module.Name(), module); err != nil {
+			log.Errorf("Add module[%s] failed. %+v", module.Name(), err)
+			return ExitFailure
+		}
+	}
+	if a.Setup != nil {
+		if err := a.Setup(a); err != nil {
+			log.Errorf("Setup application failed. %+v", err)
+			return ExitFailure
+		}
+	}
+	a.Manager.OnFailure(func(event services.Event) error {
+		a.failed.Store(true)
+		return nil
+	})
+	// 退出时间统一由 ShutdownTimeout 限制，超时后进程以 ExitForced 退出
+	a.Manager.Options.ShutdownTimeout = 0
+
+	// 模块使用独立的上下文，以便在收到退出信号后按依赖逆序退出
+	serveCtx, stopServe := context.WithCancel(context.Background())
+	defer stopServe()
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		a.Manager.StartAndServe(serveCtx)
+	}()
+
+	<-ctx.Done()
+	log.Infof("Received shutdown signal, notify modules to gracefully exit")
+	var timeout <-chan time.Time
+	if a.ShutdownTimeout > 0 {
+		timer := time.NewTimer(a.ShutdownTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	// 启动过程中收到退出信号时，待启动流程结束后再通知模块退出
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for !a.Manager.Started() {
+		select {
+		case <-ticker.C:
+		case <-force:
+			log.Warnf("Received shutdown signal again, force exit")
+			return ExitForced
+		case <-timeout:
+			log.Errorf("Modules startup exceeds %s after shutdown signal, force exit", a.ShutdownTimeout)
+			return ExitForced
+		}
+	}
+	done := a.Manager.Done(stopServe)
+
+	select {
+	case <-done:
+	case <-force:
+		log.Warnf("Received shutdown signal again, force exit")
+		return ExitForced
+	case <-timeout:
+		log.Errorf("Modules graceful exit exceeds %s, force exit", a.ShutdownTimeout)
+		return ExitForced
+	}
+	<-served
+
+	if a.failed.Load() {
+		log.Errorf("Application exited after module failures")
+		return ExitFailure
+	}
+	log.Infof("Application exited gracefully")
+	return ExitOK
+}
+
+// initLogger 根据配置中的日志字段创建日志记录器并设置为全局日志记录器，日志级别通过 log.SetLevel 控制。
+// 日志级别无法识别时返回 ErrInvalidLevel
+func (a *App[T]) initLogger() error {
+	raws := a.Configs.Raws()
+	level := log.InfoLevel
+	if value, ok := field[string](raws, FieldLogLevel); ok && value != "" {
+		if level, ok = log.LookupLevel(value); !ok {
+			return errors.Wrapf(ErrInvalidLevel, "Config field[%s] has unknown level %q", FieldLogLevel, value)
+		}
+	}
+	path, _ := field[string](raws, FieldLogPath)
+	// 日志仅由 log.SetLevel 与具名日志记录器的级别过滤，以便具名日志记录器的级别可以低于全局级别
+	option := zap.NewDefaultOption(path, level)
+	option.FollowLogLevel = true
+	if console, ok := field[bool](raws, FieldLogConsole); ok {
+		option.ConsoleLogger = console
+	}
+
+	log.SetLogger(zap.NewZapLogger(option))
+	log.SetLevel(level)
+	if conf, err := a.Configs.Conf(FieldLogLevel); err == nil {
+		BindLevel(conf)
+	}
+	return nil
+}
+
+// closeLogger 在全部模块退出后将缓冲的日志写入输出并释放日志文件，标准输出不支持同步时返回的错误被忽略
+func (a *App[T]) closeLogger() {
+	_ = log.Close()
+}
+
+// field 读取配置结构体中指定名称与类型的字段
+func field[V any](conf any, name string) (V, bool) {
+	var zero V
+	value := reflect.ValueOf(conf)
+	if value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return zero, false
+	}
+	fieldValue := value.FieldByName(name)
+	if !fieldValue.IsValid() || !fieldValue.CanInterface() {
+		return zero, false
+	}
+	typed, ok := fieldValue.Interface().(V)
+	return typed, ok
+}
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+	"github.com/wolfbolin/bolbox/pkg/services"
+)
+
+type testConfig struct {
+	LogLevel   string
+	LogConsole bool
+	Roles      string
+	Port       int
+}
+
+func newModule(name string, requires []string, run func(ctx context.Context) error) services.Module {
+	return services.NewFuncModule(name, requires, run)
+}
+
+func waitModule(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+func TestAppServe(t *testing.T) {
+	order := make(chan string, 2)
+	app := New(&testConfig{LogLevel: "warn"},
+		newModule("db", nil, func(ctx context.Context) error {
+			<-ctx.Done()
+			order <- "db"
+			return nil
+		}),
+		newModule("api", []string{"db"}, func(ctx context.Context) error {
+			<-ctx.Done()
+			order <- "api"
+			return nil
+		}),
+	)
+	app.Configs.Options.ParseFlows = nil
+	setup := false
+	app.Setup = func(app *App[testConfig]) error {
+		setup = true
+		return nil
+	}
+
+	ctx, stop := context.WithCancel(context.TODO())
+	go func() {
+		for !app.Manager.Started() {
+			time.Sleep(time.Millisecond)
+		}
+		stop()
+	}()
+	assert.Equal(t, ExitOK, app.serve(ctx, nil))
+	assert.True(t, setup)
+	assert.Equal(t, "api", <-order)
+	assert.Equal(t, "db", <-order)
+}
+
+func TestAppExitCode(t *testing.T) {
+	// 模块未经通知退出时进程以 ExitFailure 退出
+	app := New(&testConfig{},
+		newModule("broken", nil, func(ctx context.Context) error {
+			return errors.New("crashed")
+		}),
+	)
+	app.Configs.Options.ParseFlows = nil
+	ctx, stop := context.WithCancel(context.TODO())
+	app.Manager.OnFailure(func(event services.Event) error {
+		stop()
+		return nil
+	})
+	assert.Equal(t, ExitFailure, app.serve(ctx, nil))
+
+	// 无法识别的日志级别属于配置错误
+	app = New(&testConfig{LogLevel: "verbose"})
+	app.Configs.Options.ParseFlows = nil
+	assert.Equal(t, ExitConfig, app.serve(context.TODO(), nil))
+	assert.False(t, app.Manager.Started())
+
+	// Setup 返回错误时不启动模块
+	app = New(&testConfig{LogLevel: "warning"})
+	app.Configs.Options.ParseFlows = nil
+	app.Setup = func(app *App[testConfig]) error {
+		return errors.New("setup failed")
+	}
+	assert.Equal(t, ExitFailure, app.serve(context.TODO(), nil))
+	assert.False(t, app.Manager.Started())
+}
+
+func TestAppForceExit(t *testing.T) {
+	release := make(chan struct{}) // 测试结束时释放无法优雅退出的模块
+	defer close(release)
+	app := New(&testConfig{},
+		newModule("stuck", nil, func(ctx context.Context) error {
+			<-ctx.Done()
+			<-release
+			return nil
+		}),
+	)
+	app.Configs.Options.ParseFlows = nil
+	ctx, stop := context.WithCancel(context.TODO())
+	force := make(chan struct{}, 1)
+	go func() {
+		for !app.Manager.Started() {
+			time.Sleep(time.Millisecond)
+		}
+		stop()
+		force <- struct{}{}
+	}()
+	assert.Equal(t, ExitForced, app.serve(ctx, force))
+
+	// 优雅退出超时
+	app = New(&testConfig{},
+		newModule("stuck", nil, func(ctx context.Context) error {
+			<-ctx.Done()
+			<-release
+			return nil
+		}),
+	)
+	app.Configs.Options.ParseFlows = nil
+	assert.Equal(t, app.Manager.Options.ShutdownTimeout, app.ShutdownTimeout)
+	app.ShutdownTimeout = 20 * time.Millisecond
+	ctx, stop = context.WithCancel(context.TODO())
+	stop()
+	assert.Equal(t, ExitForced, app.serve(ctx, nil))
+}
+
+func TestField(t *testing.T) {
+	conf := &testConfig{LogLevel: "DEBUG", Port: 80}
+	level, ok := field[string](conf, FieldLogLevel)
+	assert.True(t, ok)
+	assert.Equal(t, "DEBUG", level)
+
+	_, ok = field[string](conf, "Port")
+	assert.False(t, ok)
+	_, ok = field[string](conf, FieldLogPath)
+	assert.False(t, ok)
+	_, ok = field[string](3, FieldLogPath)
+	assert.False(t, ok)
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/wolfbolin/bolbox/pkg/configs"
+	"github.com/wolfbolin/bolbox/pkg/errors"
+	"github.com/wolfbolin/bolbox/pkg/log"
+	"github.com/wolfbolin/bolbox/pkg/mix"
+)
+
+// levelRequest 通过 LevelHandler 调整日志级别的请求
+type levelRequest struct {
+	Level  string `json:"level"`
+	Logger string `json:"logger,omitempty"` // 具名日志记录器的名称，为空时调整全局级别
+	TTL    string `json:"ttl,omitempty"`    // 级别的有效时间，如 "5m"，到期后恢复原级别，为空时永久生效
+}
+
+// levelResponse LevelHandler 的响应
+type levelResponse struct {
+	Level   string            `json:"level"`
+	Loggers map[string]string `json:"loggers"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// LevelHandler 返回在运行时查询与调整日志级别的 http.Handler。
+//
+//	GET     返回全局级别与具名日志记录器的级别
+//	PUT     使用 {"level": "debug", "logger": "db", "ttl": "5m"} 调整级别，logger 为空时调整全局级别，指定 ttl 时到期后恢复
+//	DELETE  移除查询参数 logger 指定的具名日志记录器的级别
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			var request levelRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				writeLevels(w, http.StatusBadRequest, errors.Wrapf(err, "Invalid request body"))
+				return
+			}
+			level, ok := log.LookupLevel(request.Level)
+			if !ok {
+				writeLevels(w, http.StatusBadRequest, errors.Wrapf(ErrInvalidLevel, "Unknown level %q", request.Level))
+				return
+			}
+			var ttl time.Duration
+			if request.TTL != "" {
+				var err error
+				if ttl, err = time.ParseDuration(request.TTL); err != nil || ttl <= 0 {
+					writeLevels(w, http.StatusBadRequest, errors.Errorf("Invalid ttl %q", request.TTL))
+					return
+				}
+			}
+			log.SetLevelFor(request.Logger, level, ttl)
+			log.Infow("Log level is changed", "logger", request.Logger, "level", level.String(), "ttl", request.TTL)
+		case http.MethodDelete:
+			name := r.URL.Query().Get("logger")
+			if name == "" {
+				writeLevels(w, http.StatusBadRequest, errors.New("Query parameter logger is required"))
+				return
+			}
+			log.ResetLevel(name)
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST, DELETE")
+			writeLevels(w, http.StatusMethodNotAllowed, errors.Errorf("Method %s is not allowed", r.Method))
+			return
+		}
+		writeLevels(w, http.StatusOK, nil)
+	})
+}
+
+func writeLevels(w http.ResponseWriter, code int, err error) {
+	response := levelResponse{
+		Level:   log.GetLevel().String(),
+		Loggers: make(map[string]string),
+	}
+	for name, level := range log.NamedLevels() {
+		response.Loggers[name] = level.String()
+	}
+	if err != nil {
+		response.Error = err.Error()
+	}
+	mix.HttpRsp(w).Header("Content-Type", "application/json").Code(code).Json(response)
+}
+
+// BindLevel 在配置变更时同步调整全局日志级别，配置的取值可以是级别名称或 log.Level
+func BindLevel(conf *configs.Config) {
+	conf.OnChange(func(value any) {
+		if level, ok := toLevel(value); ok {
+			log.SetLevel(level)
+			return
+		}
+		log.Errorf("Log level config has invalid value %v", value)
+	})
+}
+
+// toLevel 将 "info" 等级别名称或整数转换为 log.Level
+func toLevel(value any) (log.Level, bool) {
+	if name, ok := value.(string); ok {
+		return log.LookupLevel(name)
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		level := log.Level(rv.Int())
+		return level, level >= log.DebugLevel && level <= log.FatalLevel
+	default:
+		return log.DebugLevel, false
+	}
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wolfbolin/bolbox/pkg/configs"
+	"github.com/wolfbolin/bolbox/pkg/log"
+)
+
+func TestLevelHandler(t *testing.T) {
+	defer log.SetLevel(log.DebugLevel)
+	defer log.ResetLevel("db")
+	handler := LevelHandler()
+	serve := func(method, target, body string) (int, string) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w.Code, strings.TrimSpace(w.Body.String())
+	}
+
+	code, body := serve(http.MethodPut, "/", `{"level": "warning"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"level":"WARN","loggers":{}}`, body)
+	code, body = serve(http.MethodPut, "/", `{"level": "debug", "logger": "db", "ttl": "1h"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"level":"WARN","loggers":{"db":"DEBUG"}}`, body)
+	code, body = serve(http.MethodGet, "/", "")
+	assert.Equal(t, `{"level":"WARN","loggers":{"db":"DEBUG"}}`, body)
+
+	code, body = serve(http.MethodPut, "/", `{"level": "verbose"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, body, `Unknown level \"verbose\"`)
+	code, _ = serve(http.MethodPut, "/", `{"level": "info", "ttl": "soon"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = serve(http.MethodPatch, "/", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+
+	code, body = serve(http.MethodDelete, "/?logger=db", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"level":"WARN","loggers":{}}`, body)
+}
+
+type levelConfig struct {
+	LogLevel string
+}
+
+func TestBindLevel(t *testing.T) {
+	defer log.SetLevel(log.DebugLevel)
+	manager, err := configs.NewManager(&levelConfig{LogLevel: "INFO"}).Parse()
+	assert.Nil(t, err)
+	conf, err := manager.Conf("LogLevel")
+	assert.Nil(t, err)
+	BindLevel(conf)
+
+	manager.ParseMap(map[string]string{"LogLevel": "error"})
+	assert.Eventually(t, func() bool {
+		return log.GetLevel() == log.ErrorLevel
+	}, time.Second, 5*time.Millisecond)
+}
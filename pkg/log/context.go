@@ -0,0 +1,142 @@
+package log
+
+import (
+	"context"
+	"fmt"
+)
+
+// FieldLogger is an optional interface for logging components that can create child loggers.
+// The child logger appends the given keyvals to every log it prints.
+type FieldLogger interface {
+	With(keyvals ...interface{}) Logger
+}
+
+type contextKey struct{}
+
+// With returns a child logger of the global logger that appends keyvals to every log
+func With(keyvals ...interface{}) Logger {
+	return WithLogger(getLogger(), keyvals...)
+}
+
+// WithLogger returns a child logger of the given logger that appends keyvals to every log.
+// Logging components that do not implement FieldLogger are wrapped to append the keyvals.
+func WithLogger(logger Logger, keyvals ...interface{}) Logger {
+	if len(keyvals) == 0 {
+		return logger
+	}
+	if fieldLogger, ok := logger.(FieldLogger); ok {
+		return fieldLogger.With(keyvals...)
+	}
+	return &withLogger{
+		Logger:  logger,
+		keyvals: append([]interface{}{}, keyvals...),
+	}
+}
+
+// WithContext returns a copy of ctx that carries the logger
+func WithContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// WithFields returns a copy of ctx that carries a child logger of the logger in ctx appending keyvals
+func WithFields(ctx context.Context, keyvals ...interface{}) context.Context {
+	return WithContext(ctx, WithLogger(FromContext(ctx), keyvals...))
+}
+
+// FromContext returns the logger carried by ctx, or the global logger if there is none
+func FromContext(ctx context.Context) Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(contextKey{}).(Logger); ok {
+			return logger
+		}
+	}
+	return getLogger()
+}
+
+// withLogger appends keyvals to the logs of a logging component that does not implement FieldLogger
+type withLogger struct {
+	Logger
+	keyvals []interface{}
+}
+
+func (w *withLogger) Log(level Level, msg string, keyvals ...interface{}) {
+	w.Logger.Log(level, msg, append(append([]interface{}{}, w.keyvals...), keyvals...)...)
+}
+
+func (w *withLogger) With(keyvals ...interface{}) Logger {
+	return &withLogger{
+		Logger:  w.Logger,
+		keyvals: append(append([]interface{}{}, w.keyvals...), keyvals...),
+	}
+}
+
+// DebugContext is used to print debug level logs with the logger carried by ctx
+func DebugContext(ctx context.Context, msg string, keyvals ...interface{}) {
+	if logger := leveled(FromContext(ctx), DebugLevel); logger != nil {
+		logger.Log(DebugLevel, msg, keyvals...)
+	}
+}
+
+// DebugfContext is used to print formatted debug level logs with the logger carried by ctx
+func DebugfContext(ctx context.Context, format string, args ...interface{}) {
+	if logger := leveled(FromContext(ctx), DebugLevel); logger != nil {
+		logger.Log(DebugLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+// InfoContext is used to print info level logs with the logger carried by ctx
+func InfoContext(ctx context.Context, msg string, keyvals ...interface{}) {
+	if logger := leveled(FromContext(ctx), InfoLevel); logger != nil {
+		logger.Log(InfoLevel, msg, keyvals...)
+	}
+}
+
+// InfofContext is used to print formatted info level logs with the logger carried by ctx
+func InfofContext(ctx context.Context, format string, args ...interface{}) {
+	if logger := leveled(FromContext(ctx), InfoLevel); logger != nil {
+		logger.Log(InfoLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+// WarnContext is used to print warn level logs with the logger carried by ctx
+func WarnContext(ctx context.Context, msg string, keyvals ...interface{}) {
+	if logger := leveled(FromContext(ctx), WarnLevel); logger != nil {
+		logger.Log(WarnLevel, msg, keyvals...)
+	}
+}
+
+// WarnfContext is used to print formatted warn level logs with the logger carried by ctx
+func WarnfContext(ctx context.Context, format string, args ...interface{}) {
+	if logger := leveled(FromContext(ctx), WarnLevel); logger != nil {
+		logger.Log(WarnLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+// ErrorContext is used to print error level logs with the logger carried by ctx
+func ErrorContext(ctx context.Context, msg string, keyvals ...interface{}) {
+	if logger := leveled(FromContext(ctx), ErrorLevel); logger != nil {
+		logger.Log(ErrorLevel, msg, keyvals...)
+	}
+}
+
+// ErrorfContext is used to print formatted error level logs with the logger carried by ctx
+func ErrorfContext(ctx context.Context, format string, args ...interface{}) {
+	if logger := leveled(FromContext(ctx), ErrorLevel); logger != nil {
+		logger.Log(ErrorLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+// PanicContext is used to print panic level logs with the logger carried by ctx
+// This function panics with a *PanicError after the log is printed, whichever logging component is used
+func PanicContext(ctx context.Context, msg string, keyvals ...interface{}) {
+	enabledFor(FromContext(ctx), PanicLevel).Log(PanicLevel, msg, keyvals...)
+	panic(newPanicError(msg, keyvals))
+}
+
+// FatalContext is used to print fatal level logs with the logger carried by ctx
+// This function exits the process with code 1 after the log is printed, whichever logging component is used.
+// The exit function can be replaced by SetExitFunc
+func FatalContext(ctx context.Context, msg string, keyvals ...interface{}) {
+	enabledFor(FromContext(ctx), FatalLevel).Log(FatalLevel, msg, keyvals...)
+	exit(1)
+}
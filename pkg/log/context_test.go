@@ -0,0 +1,62 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordLogger struct {
+	keyvals [][]interface{}
+}
+
+func (r *recordLogger) Log(level Level, msg string, keyvals ...interface{}) {
+	r.keyvals = append(r.keyvals, keyvals)
+}
+
+func (r *recordLogger) Enabled(level Level) bool {
+	return true
+}
+
+func TestWithLogger(t *testing.T) {
+	recorder := &recordLogger{}
+	child := WithLogger(recorder, "service", "api")
+	child.Log(InfoLevel, "hello", "user", 1)
+	WithLogger(child, "request", "r1").Log(InfoLevel, "hello")
+	recorder.Log(InfoLevel, "hello")
+
+	assert.Equal(t, [][]interface{}{
+		{"service", "api", "user", 1},
+		{"service", "api", "request", "r1"},
+		nil,
+	}, recorder.keyvals)
+	assert.Same(t, recorder, WithLogger(recorder))
+
+	// DefaultLogger implements FieldLogger, child loggers do not affect the parent
+	parent := &DefaultLogger{}
+	assert.Equal(t, []interface{}{"a", 1}, parent.With("a", 1).(*DefaultLogger).fields)
+	assert.Empty(t, parent.fields)
+}
+
+func TestContextLogger(t *testing.T) {
+	recorder := &recordLogger{}
+	SetLogger(recorder)
+	defer SetLogger(&DefaultLogger{})
+
+	InfoContext(context.TODO(), "global")
+	ctx := WithFields(context.TODO(), "trace", "t1")
+	ctx = WithFields(ctx, "request", "r1")
+	InfoContext(ctx, "scoped", "user", 1)
+	ErrorfContext(ctx, "failed %d", 1)
+
+	assert.Equal(t, [][]interface{}{
+		nil,
+		{"trace", "t1", "request", "r1", "user", 1},
+		{"trace", "t1", "request", "r1"},
+	}, recorder.keyvals)
+
+	other := &recordLogger{}
+	assert.Same(t, other, FromContext(WithContext(context.TODO(), other)))
+	assert.Same(t, recorder, FromContext(nil))
+}
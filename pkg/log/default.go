@@ -1,23 +1,234 @@
 package log
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
 	"log"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 )
 
-// DefaultLogger provides the default implementation of the logging component
+// BadKey is the key of a value without key, which is the last element of odd-length keyvals
+const BadKey = "!BADKEY"
+
+// DefaultTimeFormat is the timestamp format of DefaultLogger, which is the same as the standard log package
+const DefaultTimeFormat = "2006/01/02 15:04:05"
+
+// Format is the output format of DefaultLogger
+type Format int
+
+const (
+	// TextFormat prints logs like "2006/01/02 15:04:05 INFO | message | key=value"
+	TextFormat Format = iota
+	// JSONFormat prints logs as JSON objects, one per line
+	JSONFormat
+)
+
+// DefaultLogger provides the default implementation of the logging component.
+// It depends only on the standard library, and its zero value prints text logs of all levels
+// into the output of the standard log package.
 type DefaultLogger struct {
+	Output     io.Writer // the output of logs, nil means the output of the standard log package
+	Format     Format    // the output format
+	Level      Level     // the minimum level of printed logs
+	TimeFormat string    // the timestamp layout, empty means DefaultTimeFormat, "-" omits the timestamp
+	Caller     bool      // whether to print file:line of the caller
+	CallSkip   int       // number of extra frames to skip when the logger is wrapped
+
+	name   string // the name of the named logger whose level the logger follows, empty for the global level
+	fields []interface{}
 }
 
-// Log implements the log output of the default log component
+var _ FieldLogger = (*DefaultLogger)(nil)
+
+// Log implements the log output of the default log component.
+// Values of error type are printed with their messages, and with their details like stack traces
+// printed by %+v if there are any.
 func (d *DefaultLogger) Log(level Level, msg string, keyvals ...interface{}) {
-	if len(keyvals) == 0 {
-		log.Println(level.String(), "|", msg, " ")
+	if !d.Enabled(level) {
+		return
+	}
+	if len(d.fields) != 0 {
+		keyvals = append(append([]interface{}{}, d.fields...), keyvals...)
+	}
+	entry := defaultEntry{
+		level:   level,
+		msg:     msg,
+		keyvals: keyvals,
+	}
+	if d.TimeFormat != "-" {
+		entry.time = time.Now().Format(d.timeFormat())
+	}
+	if d.Caller {
+		if _, file, line, ok := runtime.Caller(2 + d.CallSkip); ok {
+			entry.caller = filepath.Base(file) + ":" + strconv.Itoa(line)
+		}
+	}
+
+	var buf bytes.Buffer
+	if d.Format == JSONFormat {
+		entry.writeJSON(&buf)
 	} else {
-		log.Println(level.String(), "|", msg, " ", keyvals)
+		entry.writeText(&buf)
 	}
+	_, _ = d.output().Write(buf.Bytes())
 }
 
-// Enabled implements log level queries for default log components
+// Enabled implements log level queries for default log components,
+// both its own level and the level set by SetLevel or SetLevelFor are considered
 func (d *DefaultLogger) Enabled(level Level) bool {
-	return true
+	return level >= d.Level && LevelEnabled(d.name, level)
+}
+
+// With returns a child logger that appends keyvals to every log,
+// the child follows the level of the named logger if keyvals has NameKey
+func (d *DefaultLogger) With(keyvals ...interface{}) Logger {
+	child := *d
+	child.name = LoggerName(d.name, keyvals...)
+	child.fields = append(append([]interface{}{}, d.fields...), keyvals...)
+	return &child
+}
+
+func (d *DefaultLogger) output() io.Writer {
+	if d.Output == nil {
+		return log.Writer()
+	}
+	return d.Output
+}
+
+func (d *DefaultLogger) timeFormat() string {
+	if d.TimeFormat == "" {
+		return DefaultTimeFormat
+	}
+	return d.TimeFormat
+}
+
+// defaultEntry is a log to be formatted by DefaultLogger
+type defaultEntry struct {
+	time    string
+	level   Level
+	caller  string
+	msg     string
+	keyvals []interface{}
+}
+
+// pairs calls f with every key and value of keyvals, the value of odd-length keyvals gets BadKey
+func (e *defaultEntry) pairs(f func(key string, value interface{})) {
+	for i := 0; i < len(e.keyvals); i += 2 {
+		if i+1 == len(e.keyvals) {
+			f(BadKey, e.keyvals[i])
+			return
+		}
+		f(fmt.Sprint(e.keyvals[i]), e.keyvals[i+1])
+	}
+}
+
+// writeText writes the entry like "time LEVEL | caller | message | key=value", details of errors follow the line
+func (e *defaultEntry) writeText(buf *bytes.Buffer) {
+	if e.time != "" {
+		buf.WriteString(e.time)
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(e.level.String())
+	if e.caller != "" {
+		buf.WriteString(" | ")
+		buf.WriteString(e.caller)
+	}
+	buf.WriteString(" | ")
+	buf.WriteString(e.msg)
+
+	details := make([]string, 0)
+	separator := " | "
+	e.pairs(func(key string, value interface{}) {
+		buf.WriteString(separator)
+		separator = " "
+		buf.WriteString(quoteText(key))
+		buf.WriteByte('=')
+		if err, ok := value.(error); ok {
+			buf.WriteString(quoteText(err.Error()))
+			if detail := errorDetail(err); detail != "" {
+				details = append(details, detail)
+			}
+			return
+		}
+		buf.WriteString(quoteText(fmt.Sprint(value)))
+	})
+	buf.WriteByte('\n')
+	for _, detail := range details {
+		buf.WriteString(detail)
+		buf.WriteByte('\n')
+	}
+}
+
+// writeJSON writes the entry as a JSON object, details of errors are written with the key suffixed by "Verbose"
+func (e *defaultEntry) writeJSON(buf *bytes.Buffer) {
+	buf.WriteByte('{')
+	separator := ""
+	write := func(key string, value interface{}) {
+		buf.WriteString(separator)
+		separator = ","
+		writeJSONValue(buf, key)
+		buf.WriteByte(':')
+		writeJSONValue(buf, value)
+	}
+	if e.time != "" {
+		write("time", e.time)
+	}
+	write("level", e.level.String())
+	if e.caller != "" {
+		write("caller", e.caller)
+	}
+	write("msg", e.msg)
+	e.pairs(func(key string, value interface{}) {
+		if err, ok := value.(error); ok {
+			write(key, err.Error())
+			if detail := errorDetail(err); detail != "" {
+				write(key+"Verbose", detail)
+			}
+			return
+		}
+		write(key, value)
+	})
+	buf.WriteString("}\n")
+}
+
+// writeJSONValue writes the value as JSON, values that cannot be marshaled are written as strings
+func writeJSONValue(buf *bytes.Buffer, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(value))
+	}
+	buf.Write(data)
+}
+
+// errorDetail returns the details of the error printed by %+v, like stack traces,
+// or an empty string if there are no details other than the message
+func errorDetail(err error) string {
+	if _, ok := err.(fmt.Formatter); !ok {
+		return ""
+	}
+	detail := fmt.Sprintf("%+v", err)
+	if detail == err.Error() {
+		return ""
+	}
+	return detail
+}
+
+// quoteText quotes the text if it is empty or contains spaces, quotes, equal signs or control characters
+func quoteText(text string) string {
+	if text == "" {
+		return `""`
+	}
+	if strings.IndexFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '"' || r == '=' || !unicode.IsPrint(r)
+	}) >= 0 {
+		return strconv.Quote(text)
+	}
+	return text
 }
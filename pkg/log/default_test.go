@@ -0,0 +1,75 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+)
+
+func TestDefaultLoggerText(t *testing.T) {
+	var buf bytes.Buffer
+	logger := &DefaultLogger{Output: &buf, Level: InfoLevel, TimeFormat: "-"}
+	logger.Log(DebugLevel, "filtered")
+	logger.Log(InfoLevel, "hello world", "user", "a b", "n", 1, "empty", "", "odd")
+	logger.With("module", "db").Log(WarnLevel, "plain", "err", stderrors.New("closed"))
+	assert.False(t, logger.Enabled(DebugLevel))
+	assert.Equal(t, "INFO | hello world | user=\"a b\" n=1 empty=\"\" !BADKEY=odd\n"+
+		"WARN | plain | module=db err=closed\n", buf.String())
+
+	// 带有堆栈的错误在日志行之后输出 %+v 格式的详情
+	buf.Reset()
+	logger.Log(ErrorLevel, "failed", "err", errors.New("boom"))
+	lines := strings.SplitN(buf.String(), "\n", 2)
+	assert.Equal(t, "ERROR | failed | err=boom", lines[0])
+	assert.Contains(t, lines[1], "default_test.go")
+
+	buf.Reset()
+	logger.Caller, logger.TimeFormat = true, time.RFC3339
+	SetLogger(logger)
+	defer SetLogger(&DefaultLogger{})
+	Info("caller")
+	assert.Regexp(t, `^\d{4}-\d{2}-\d{2}T\S+ INFO \| default_test.go:\d+ \| caller\n$`, buf.String())
+}
+
+func TestDefaultLoggerJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := &DefaultLogger{Output: &buf, Format: JSONFormat, TimeFormat: "-"}
+	logger.Log(WarnLevel, "hello", "user", 1, "tags", []string{"a"}, "err", errors.New("boom"), "odd")
+
+	line := buf.String()
+	assert.True(t, strings.HasPrefix(line, `{"level":"WARN","msg":"hello","user":1,"tags":["a"],"err":"boom","errVerbose":`))
+	var entry map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(line), &entry))
+	assert.Equal(t, "odd", entry[BadKey])
+	assert.Contains(t, entry["errVerbose"], "default_test.go")
+}
+
+func TestDefaultLoggerGlobalLevel(t *testing.T) {
+	defer SetLevel(DebugLevel)
+	defer ResetLevel("db")
+	var buf bytes.Buffer
+	logger := &DefaultLogger{Output: &buf, TimeFormat: "-"}
+	SetLevel(WarnLevel)
+	SetLevelFor("db", DebugLevel, 0)
+
+	// 直接使用日志记录器时同样遵循全局级别与具名日志记录器的级别
+	logger.Log(InfoLevel, "filtered")
+	logger.With(NameKey, "db").Log(DebugLevel, "named")
+	logger.Log(WarnLevel, "warn")
+	assert.False(t, logger.Enabled(InfoLevel))
+	assert.Equal(t, "DEBUG | named | logger=db\nWARN | warn\n", buf.String())
+
+	// 通过 Named 获得的日志记录器不受全局级别的限制
+	buf.Reset()
+	SetLogger(logger)
+	defer SetLogger(&DefaultLogger{})
+	Named("db").Log(DebugLevel, "debug")
+	assert.Equal(t, "DEBUG | debug | logger=db\n", buf.String())
+}
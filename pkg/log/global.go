@@ -2,13 +2,17 @@ package log
 
 import (
 	"fmt"
-	"sync"
+	"io"
+	"sync/atomic"
 )
 
-var (
-	globalLogger Logger
-	mutex        sync.Mutex
-)
+// globalLogger holds the global logging component
+var globalLogger atomic.Pointer[loggerHolder]
+
+// loggerHolder wraps the logger since atomic.Pointer cannot hold an interface value directly
+type loggerHolder struct {
+	Logger
+}
 
 func init() {
 	SetLogger(&DefaultLogger{})
@@ -16,108 +20,175 @@ func init() {
 
 // SetLogger is configured here to use the global logging component
 func SetLogger(logger Logger) {
-	mutex.Lock()
-	defer mutex.Unlock()
-	globalLogger = logger
+	globalLogger.Store(&loggerHolder{Logger: logger})
+}
+
+// GetLogger returns the current global logging component
+func GetLogger() Logger {
+	return getLogger()
+}
+
+// getLogger returns the current global logging component
+func getLogger() Logger {
+	return globalLogger.Load().Logger
+}
+
+// Syncer is an optional interface for logging components that buffer logs before writing them
+type Syncer interface {
+	Sync() error
+}
+
+// Sync flushes the buffered logs of the global logger if it implements Syncer
+func Sync() error {
+	if syncer, ok := getLogger().(Syncer); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+// Close flushes the buffered logs and releases the resources of the global logger if it implements io.Closer,
+// otherwise it works like Sync. It is meant to be called on the shutdown path after all modules exit.
+func Close() error {
+	if closer, ok := getLogger().(io.Closer); ok {
+		return closer.Close()
+	}
+	return Sync()
 }
 
-// Enabled is used to confirm the log level of the current configuration
+// Enabled is used to confirm the log level of the current configuration,
+// both the global level and the level of the logging component are considered
 func Enabled(level Level) bool {
-	return globalLogger.Enabled(level)
+	logger := enabledFor(getLogger(), level)
+	return logger != nil && logger.Enabled(level)
 }
 
 // Debug is used to print debug logs
 func Debug(msg string) {
-	globalLogger.Log(DebugLevel, msg)
+	if logger := leveled(getLogger(), DebugLevel); logger != nil {
+		logger.Log(DebugLevel, msg)
+	}
 }
 
 // Debugf is used to print formatted debug level logs
 func Debugf(format string, args ...interface{}) {
-	globalLogger.Log(DebugLevel, fmt.Sprintf(format, args...))
+	if logger := leveled(getLogger(), DebugLevel); logger != nil {
+		logger.Log(DebugLevel, fmt.Sprintf(format, args...))
+	}
 }
 
 // Debugw is used to print debug level logs containing additional kv information
 func Debugw(msg string, keyvals ...interface{}) {
-	globalLogger.Log(DebugLevel, msg, keyvals...)
+	if logger := leveled(getLogger(), DebugLevel); logger != nil {
+		logger.Log(DebugLevel, msg, keyvals...)
+	}
 }
 
 // Info is used to print info level logs
 func Info(msg string) {
-	globalLogger.Log(InfoLevel, msg)
+	if logger := leveled(getLogger(), InfoLevel); logger != nil {
+		logger.Log(InfoLevel, msg)
+	}
 }
 
 // Infof is used to print formatted info level logs
 func Infof(format string, args ...interface{}) {
-	globalLogger.Log(InfoLevel, fmt.Sprintf(format, args...))
+	if logger := leveled(getLogger(), InfoLevel); logger != nil {
+		logger.Log(InfoLevel, fmt.Sprintf(format, args...))
+	}
 }
 
 // Infow is used to print info level logs containing additional kv information
 func Infow(msg string, keyvals ...interface{}) {
-	globalLogger.Log(InfoLevel, msg, keyvals...)
+	if logger := leveled(getLogger(), InfoLevel); logger != nil {
+		logger.Log(InfoLevel, msg, keyvals...)
+	}
 }
 
 // Warn is used to print warning level logs
 func Warn(msg string) {
-	globalLogger.Log(WarnLevel, msg)
+	if logger := leveled(getLogger(), WarnLevel); logger != nil {
+		logger.Log(WarnLevel, msg)
+	}
 }
 
 // Warnf is used to print formatted warning level logs
 func Warnf(format string, args ...interface{}) {
-	globalLogger.Log(WarnLevel, fmt.Sprintf(format, args...))
+	if logger := leveled(getLogger(), WarnLevel); logger != nil {
+		logger.Log(WarnLevel, fmt.Sprintf(format, args...))
+	}
 }
 
 // Warnw is used to print warning level logs containing additional kv information
 func Warnw(msg string, keyvals ...interface{}) {
-	globalLogger.Log(WarnLevel, msg, keyvals...)
+	if logger := leveled(getLogger(), WarnLevel); logger != nil {
+		logger.Log(WarnLevel, msg, keyvals...)
+	}
 }
 
 // Error is used to print error level logs
 func Error(msg string) {
-	globalLogger.Log(ErrorLevel, msg)
+	if logger := leveled(getLogger(), ErrorLevel); logger != nil {
+		logger.Log(ErrorLevel, msg)
+	}
 }
 
 // Errorf is used to print formatted error level logs
 func Errorf(format string, args ...interface{}) {
-	globalLogger.Log(ErrorLevel, fmt.Sprintf(format, args...))
+	if logger := leveled(getLogger(), ErrorLevel); logger != nil {
+		logger.Log(ErrorLevel, fmt.Sprintf(format, args...))
+	}
 }
 
 // Errorw is used to print error level logs containing additional kv information
 func Errorw(msg string, keyvals ...interface{}) {
-	globalLogger.Log(ErrorLevel, msg, keyvals...)
+	if logger := leveled(getLogger(), ErrorLevel); logger != nil {
+		logger.Log(ErrorLevel, msg, keyvals...)
+	}
 }
 
 // Panic is used to print panic level logs
-// This function will call the panic(err) after the log is printed
+// This function panics with a *PanicError after the log is printed, whichever logging component is used
 func Panic(msg string) {
-	globalLogger.Log(PanicLevel, msg)
+	getLogger().Log(PanicLevel, msg)
+	panic(newPanicError(msg, nil))
 }
 
 // Panicf is used to print formatted panic level logs
-// This function will call the panic(err) after the log is printed
+// This function panics with a *PanicError after the log is printed, whichever logging component is used
 func Panicf(format string, args ...interface{}) {
-	globalLogger.Log(PanicLevel, fmt.Sprintf(format, args...))
+	msg := fmt.Sprintf(format, args...)
+	getLogger().Log(PanicLevel, msg)
+	panic(newPanicError(msg, nil))
 }
 
-// Panicw is used to print painc level logs containing additional kv information
-// This function will call the panic(err) after the log is printed
+// Panicw is used to print panic level logs containing additional kv information
+// This function panics with a *PanicError after the log is printed, whichever logging component is used
 func Panicw(msg string, keyvals ...interface{}) {
-	globalLogger.Log(PanicLevel, msg, keyvals...)
+	getLogger().Log(PanicLevel, msg, keyvals...)
+	panic(newPanicError(msg, keyvals))
 }
 
 // Fatal is used to print fatal level logs
-// This function will call the os.Exit(1) to exit the process after the log is printed
+// This function exits the process with code 1 after the log is printed, whichever logging component is used.
+// The exit function can be replaced by SetExitFunc
 func Fatal(msg string) {
-	globalLogger.Log(FatalLevel, msg)
+	getLogger().Log(FatalLevel, msg)
+	exit(1)
 }
 
 // Fatalf is used to print formatted fatal level logs
-// This function will call the os.Exit(1) to exit the process after the log is printed
+// This function exits the process with code 1 after the log is printed, whichever logging component is used.
+// The exit function can be replaced by SetExitFunc
 func Fatalf(format string, args ...interface{}) {
-	globalLogger.Log(FatalLevel, fmt.Sprintf(format, args...))
+	msg := fmt.Sprintf(format, args...)
+	getLogger().Log(FatalLevel, msg)
+	exit(1)
 }
 
 // Fatalw is used to print fatal level logs containing additional kv information
-// This function will call the os.Exit(1) to exit the process after the log is printed
+// This function exits the process with code 1 after the log is printed, whichever logging component is used.
+// The exit function can be replaced by SetExitFunc
 func Fatalw(msg string, keyvals ...interface{}) {
-	globalLogger.Log(FatalLevel, msg, keyvals...)
+	getLogger().Log(FatalLevel, msg, keyvals...)
+	exit(1)
 }
@@ -0,0 +1,80 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type closeLogger struct {
+	recordLogger
+	synced, closed int
+}
+
+func (c *closeLogger) Sync() error {
+	c.synced += 1
+	return nil
+}
+
+func (c *closeLogger) Close() error {
+	c.closed += 1
+	return nil
+}
+
+type syncLogger struct {
+	recordLogger
+	synced int
+}
+
+func (s *syncLogger) Sync() error {
+	s.synced += 1
+	return nil
+}
+
+func TestSyncClose(t *testing.T) {
+	defer SetLogger(&DefaultLogger{})
+	assert.Nil(t, Sync())
+	assert.Nil(t, Close())
+
+	closer := &closeLogger{}
+	SetLogger(closer)
+	assert.Nil(t, Sync())
+	assert.Nil(t, Close())
+	assert.Equal(t, 1, closer.synced)
+	assert.Equal(t, 1, closer.closed)
+
+	// 未实现 io.Closer 时 Close 仅同步日志
+	syncer := &syncLogger{}
+	SetLogger(syncer)
+	assert.Nil(t, Close())
+	assert.Equal(t, 1, syncer.synced)
+}
+
+func TestSetLoggerConcurrent(t *testing.T) {
+	defer SetLogger(&DefaultLogger{})
+	loggers := []*recordLogger{{}, {}}
+	SetLogger(loggers[0])
+	assert.Same(t, loggers[0], GetLogger())
+
+	// 替换全局日志记录器的同时其他协程仍在输出日志，使用 -race 运行时不应报告数据竞争
+	done := make(chan struct{})
+	logged := make(chan struct{})
+	go func() {
+		defer close(logged)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				_ = Enabled(InfoLevel)
+				_ = GetLogger()
+			}
+		}
+	}()
+	for i := range 100 {
+		SetLogger(loggers[i%2])
+	}
+	close(done)
+	<-logged
+	assert.Same(t, loggers[1], GetLogger())
+}
@@ -0,0 +1,237 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// NameKey is the key of the name field appended to the logs of named loggers
+const NameKey = "logger"
+
+// globalLevel is the minimum level of logs printed through this package, it defaults to DebugLevel
+var globalLevel atomic.Int32
+
+// levels holds the levels of named loggers that override the global level,
+// and the levels to revert to when temporary levels expire
+var levels = struct {
+	sync.RWMutex
+	named   map[string]Level
+	reverts map[string]*levelRevert
+}{
+	named:   make(map[string]Level),
+	reverts: make(map[string]*levelRevert),
+}
+
+// levelRevert is the level a logger reverts to when its temporary level expires
+type levelRevert struct {
+	timer      *time.Timer
+	level      Level
+	overridden bool // whether the named logger overrides the global level after reverting
+}
+
+// SetLevel sets the minimum level of logs printed through this package, the context functions and named loggers.
+// It is honored by every logging component, which may still filter logs by its own level.
+// PanicLevel and FatalLevel logs are never filtered.
+func SetLevel(level Level) {
+	SetLevelFor("", level, 0)
+}
+
+// GetLevel returns the minimum level of logs printed through this package
+func GetLevel() Level {
+	return Level(globalLevel.Load())
+}
+
+// GetNamedLevel returns the minimum level of the named logger, which is the global level if not overridden
+func GetNamedLevel(name string) Level {
+	levels.RLock()
+	defer levels.RUnlock()
+	if level, ok := levels.named[name]; ok {
+		return level
+	}
+	return GetLevel()
+}
+
+// LevelEnabled reports whether logs of the level pass the level of the named logger,
+// or the global level if name is empty. Logging components call it in Enabled and Log,
+// so that SetLevel is honored when they are used directly instead of through this package.
+func LevelEnabled(name string, level Level) bool {
+	return level >= PanicLevel || level >= GetNamedLevel(name)
+}
+
+// LoggerName returns the value of the last NameKey in keyvals, or name if keyvals has no NameKey.
+// Logging components call it in With to find the named level their child loggers follow.
+func LoggerName(name string, keyvals ...interface{}) string {
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if key, ok := keyvals[i].(string); ok && key == NameKey {
+			name = fmt.Sprint(keyvals[i+1])
+		}
+	}
+	return name
+}
+
+// SetLevelFor sets the minimum level of the named logger, or the global level if name is empty.
+// If ttl is greater than 0, the level reverts to the one before the temporary change after ttl.
+func SetLevelFor(name string, level Level, ttl time.Duration) {
+	levels.Lock()
+	defer levels.Unlock()
+	revert, pending := levels.reverts[name]
+	if pending {
+		// Another temporary level is replaced, keep reverting to the level before it
+		revert.timer.Stop()
+		delete(levels.reverts, name)
+	} else {
+		revert = currentLevel(name)
+	}
+	applyLevel(name, level, true)
+	if ttl <= 0 {
+		return
+	}
+	revert.timer = time.AfterFunc(ttl, func() {
+		levels.Lock()
+		defer levels.Unlock()
+		if levels.reverts[name] == revert {
+			delete(levels.reverts, name)
+			applyLevel(name, revert.level, revert.overridden)
+		}
+	})
+	levels.reverts[name] = revert
+}
+
+// ResetLevel removes the level of the named logger, which falls back to the global level
+func ResetLevel(name string) {
+	levels.Lock()
+	defer levels.Unlock()
+	if revert, ok := levels.reverts[name]; ok {
+		revert.timer.Stop()
+		delete(levels.reverts, name)
+	}
+	delete(levels.named, name)
+}
+
+// NamedLevels returns the levels of named loggers that override the global level
+func NamedLevels() map[string]Level {
+	levels.RLock()
+	defer levels.RUnlock()
+	result := make(map[string]Level, len(levels.named))
+	for name, level := range levels.named {
+		result[name] = level
+	}
+	return result
+}
+
+// currentLevel returns the level of the named logger, the caller must hold the lock
+func currentLevel(name string) *levelRevert {
+	if name == "" {
+		return &levelRevert{level: GetLevel(), overridden: true}
+	}
+	level, ok := levels.named[name]
+	return &levelRevert{level: level, overridden: ok}
+}
+
+// applyLevel sets the level of the named logger, the caller must hold the lock
+func applyLevel(name string, level Level, overridden bool) {
+	switch {
+	case name == "":
+		globalLevel.Store(int32(level))
+	case overridden:
+		levels.named[name] = level
+	default:
+		delete(levels.named, name)
+	}
+}
+
+// LookupLevel parses the case-insensitive level name like "info" or "warning", it reports false if the name is unknown
+func LookupLevel(name string) (Level, bool) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "WARNING" {
+		return WarnLevel, true
+	}
+	for level := DebugLevel; level <= FatalLevel; level++ {
+		if level.String() == name {
+			return level, true
+		}
+	}
+	return DebugLevel, false
+}
+
+// Named returns a logger of the global logger whose level can be changed apart from the global level.
+// The name is appended to every log with the key NameKey.
+func Named(name string) Logger {
+	return &namedLogger{name: name}
+}
+
+// namedLogger filters logs by the level of its name, and follows the replacement of the global logger
+type namedLogger struct {
+	name    string
+	keyvals []interface{}
+	cache   atomic.Pointer[namedCache]
+}
+
+var _ FieldLogger = (*namedLogger)(nil)
+
+// namedCache is the child logger built from the global logger held by holder
+type namedCache struct {
+	holder *loggerHolder
+	logger Logger
+}
+
+func (n *namedLogger) Log(level Level, msg string, keyvals ...interface{}) {
+	if n.enabled(level) && !rateLimited(level, 1) {
+		n.target().Log(level, msg, keyvals...)
+	}
+}
+
+func (n *namedLogger) Enabled(level Level) bool {
+	return n.enabled(level) && n.target().Enabled(level)
+}
+
+func (n *namedLogger) With(keyvals ...interface{}) Logger {
+	return &namedLogger{
+		name:    n.name,
+		keyvals: append(append([]interface{}{}, n.keyvals...), keyvals...),
+	}
+}
+
+func (n *namedLogger) enabled(level Level) bool {
+	return LevelEnabled(n.name, level)
+}
+
+// target returns the child logger of the current global logger
+func (n *namedLogger) target() Logger {
+	holder := globalLogger.Load()
+	if cache := n.cache.Load(); cache != nil && cache.holder == holder {
+		return cache.logger
+	}
+	keyvals := append([]interface{}{NameKey, n.name}, n.keyvals...)
+	cache := &namedCache{holder: holder, logger: WithLogger(holder.Logger, keyvals...)}
+	n.cache.Store(cache)
+	return cache.logger
+}
+
+// leveled returns the logger to print logs of the level, or nil if the level is filtered out
+// or the log is suppressed by the rate limiting. It must be called by the functions called by users.
+func leveled(logger Logger, level Level) Logger {
+	logger = enabledFor(logger, level)
+	if logger == nil || rateLimited(level, 2) {
+		return nil
+	}
+	return logger
+}
+
+// enabledFor returns the logger to print logs of the level, or nil if the level is filtered out.
+// Named loggers are unwrapped so that the call depth is the same as the global logger.
+func enabledFor(logger Logger, level Level) Logger {
+	if named, ok := logger.(*namedLogger); ok {
+		if !named.enabled(level) {
+			return nil
+		}
+		return named.target()
+	}
+	if !LevelEnabled("", level) {
+		return nil
+	}
+	return logger
+}
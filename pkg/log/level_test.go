@@ -0,0 +1,56 @@
+package log
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevel(t *testing.T) {
+	recorder := &recordLogger{}
+	SetLogger(recorder)
+	defer SetLogger(&DefaultLogger{})
+	defer SetLevel(DebugLevel)
+
+	SetLevel(WarnLevel)
+	Infof("filtered %d", 1)
+	InfoContext(context.TODO(), "filtered")
+	Warnw("printed", "n", 1)
+	assert.False(t, Enabled(InfoLevel))
+	assert.True(t, Enabled(ErrorLevel))
+	assert.Equal(t, [][]interface{}{{"n", 1}}, recorder.keyvals)
+
+	// 具名日志记录器的级别覆盖全局级别，并跟随全局日志记录器的替换
+	db := Named("db")
+	SetLevelFor("db", DebugLevel, 0)
+	defer ResetLevel("db")
+	db.Log(DebugLevel, "query")
+	DebugContext(WithContext(context.TODO(), db), "query", "sql", "select")
+	Named("cache").Log(InfoLevel, "filtered")
+	assert.Equal(t, [][]interface{}{{"n", 1}, {NameKey, "db"}, {NameKey, "db", "sql", "select"}}, recorder.keyvals)
+
+	other := &recordLogger{}
+	SetLogger(other)
+	WithLogger(db, "table", "users").Log(InfoLevel, "query")
+	assert.Equal(t, [][]interface{}{{NameKey, "db", "table", "users"}}, other.keyvals)
+
+	ResetLevel("db")
+	assert.Equal(t, WarnLevel, GetNamedLevel("db"))
+	assert.False(t, db.Enabled(InfoLevel))
+}
+
+func TestLevelTTL(t *testing.T) {
+	defer SetLevel(DebugLevel)
+	SetLevel(ErrorLevel)
+	SetLevelFor("", DebugLevel, 20*time.Millisecond)
+	SetLevelFor("", InfoLevel, 20*time.Millisecond) // 连续的临时设置恢复到最初的级别
+	SetLevelFor("api", DebugLevel, 20*time.Millisecond)
+	assert.Equal(t, InfoLevel, GetLevel())
+	assert.Equal(t, DebugLevel, GetNamedLevel("api"))
+
+	assert.Eventually(t, func() bool {
+		return GetLevel() == ErrorLevel && len(NamedLevels()) == 0
+	}, time.Second, 5*time.Millisecond)
+}
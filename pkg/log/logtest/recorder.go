@@ -0,0 +1,206 @@
+package logtest
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wolfbolin/bolbox/pkg/log"
+)
+
+// Entry 记录的一条日志
+type Entry struct {
+	Time    time.Time
+	Level   log.Level
+	Message string
+	Keyvals []interface{} // 包括子日志记录器附加的 keyvals
+	Caller  string        // 调用位置，格式为 file:line
+}
+
+// Field 返回指定键对应的值，键出现多次时返回最后一个，奇数长度时最后一个值的键为 log.BadKey
+func (e Entry) Field(key string) (interface{}, bool) {
+	value, ok := e.Fields()[key]
+	return value, ok
+}
+
+// Fields 以键值对的形式返回日志的全部字段
+func (e Entry) Fields() map[string]interface{} {
+	fields := make(map[string]interface{}, (len(e.Keyvals)+1)/2)
+	for i := 0; i < len(e.Keyvals); i += 2 {
+		if i+1 == len(e.Keyvals) {
+			fields[log.BadKey] = e.Keyvals[i]
+			break
+		}
+		fields[fmt.Sprint(e.Keyvals[i])] = e.Keyvals[i+1]
+	}
+	return fields
+}
+
+// Entries 日志列表，过滤函数返回新的列表，可以链式调用
+type Entries []Entry
+
+// FilterLevel 返回指定级别的日志
+func (es Entries) FilterLevel(level log.Level) Entries {
+	return es.filter(func(e Entry) bool {
+		return e.Level == level
+	})
+}
+
+// FilterMessage 返回消息与 msg 完全相同的日志
+func (es Entries) FilterMessage(msg string) Entries {
+	return es.filter(func(e Entry) bool {
+		return e.Message == msg
+	})
+}
+
+// FilterMessageContains 返回消息包含 snippet 的日志
+func (es Entries) FilterMessageContains(snippet string) Entries {
+	return es.filter(func(e Entry) bool {
+		return strings.Contains(e.Message, snippet)
+	})
+}
+
+// FilterField 返回字段 key 的值与 value 相等（reflect.DeepEqual）的日志
+func (es Entries) FilterField(key string, value interface{}) Entries {
+	return es.filter(func(e Entry) bool {
+		field, ok := e.Field(key)
+		return ok && reflect.DeepEqual(field, value)
+	})
+}
+
+// Messages 返回全部日志的消息
+func (es Entries) Messages() []string {
+	messages := make([]string, 0, len(es))
+	for _, e := range es {
+		messages = append(messages, e.Message)
+	}
+	return messages
+}
+
+func (es Entries) filter(match func(e Entry) bool) Entries {
+	result := make(Entries, 0)
+	for _, e := range es {
+		if match(e) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// Recorder 将日志记录在内存中的日志记录器，可以在多个协程中并发使用，子日志记录器与父日志记录器共享记录
+type Recorder struct {
+	Level log.Level // 记录的最低日志级别
+
+	store   *store
+	keyvals []interface{}
+}
+
+var _ log.FieldLogger = (*Recorder)(nil)
+
+// store 日志记录器及其子日志记录器共享的日志列表
+type store struct {
+	lock    sync.Mutex
+	entries Entries
+}
+
+// New 创建记录全部级别日志的日志记录器
+func New() *Recorder {
+	return &Recorder{store: &store{}}
+}
+
+// Log 记录日志，PANIC 与 FATAL 级别的日志同样仅被记录
+func (r *Recorder) Log(level log.Level, msg string, keyvals ...interface{}) {
+	if !r.Enabled(level) {
+		return
+	}
+	entry := Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Message: msg,
+		Keyvals: append(append([]interface{}{}, r.keyvals...), keyvals...),
+		Caller:  caller(),
+	}
+	r.store.lock.Lock()
+	defer r.store.lock.Unlock()
+	r.store.entries = append(r.store.entries, entry)
+}
+
+// Enabled 检查给定的日志级别是否被记录
+func (r *Recorder) Enabled(level log.Level) bool {
+	return level >= r.Level
+}
+
+// With 返回一个在每条日志中附加 keyvals 的子日志记录器
+func (r *Recorder) With(keyvals ...interface{}) log.Logger {
+	return &Recorder{
+		Level:   r.Level,
+		store:   r.store,
+		keyvals: append(append([]interface{}{}, r.keyvals...), keyvals...),
+	}
+}
+
+// Entries 返回已记录的全部日志
+func (r *Recorder) Entries() Entries {
+	r.store.lock.Lock()
+	defer r.store.lock.Unlock()
+	return append(Entries{}, r.store.entries...)
+}
+
+// Len 返回已记录的日志数量
+func (r *Recorder) Len() int {
+	r.store.lock.Lock()
+	defer r.store.lock.Unlock()
+	return len(r.store.entries)
+}
+
+// Reset 清空已记录的日志
+func (r *Recorder) Reset() {
+	r.store.lock.Lock()
+	defer r.store.lock.Unlock()
+	r.store.entries = nil
+}
+
+// FilterLevel 返回已记录的指定级别的日志
+func (r *Recorder) FilterLevel(level log.Level) Entries {
+	return r.Entries().FilterLevel(level)
+}
+
+// FilterMessage 返回已记录的消息与 msg 完全相同的日志
+func (r *Recorder) FilterMessage(msg string) Entries {
+	return r.Entries().FilterMessage(msg)
+}
+
+// FilterField 返回已记录的字段 key 的值与 value 相等的日志
+func (r *Recorder) FilterField(key string, value interface{}) Entries {
+	return r.Entries().FilterField(key, value)
+}
+
+// logPackages 调用位置需要跳过的日志包
+var logPackages = []string{
+	"github.com/wolfbolin/bolbox/pkg/log.",
+	"github.com/wolfbolin/bolbox/pkg/log/logtest.",
+}
+
+// caller 返回日志包之外的第一个调用位置
+func caller() string {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		inLog := false
+		for _, prefix := range logPackages {
+			inLog = inLog || strings.HasPrefix(frame.Function, prefix)
+		}
+		if !inLog {
+			return filepath.Base(frame.File) + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
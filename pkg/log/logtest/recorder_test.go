@@ -0,0 +1,59 @@
+package logtest_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wolfbolin/bolbox/pkg/log"
+	"github.com/wolfbolin/bolbox/pkg/log/logtest"
+)
+
+func TestRecorder(t *testing.T) {
+	recorder := logtest.New()
+	recorder.Level = log.InfoLevel
+	recorder.Log(log.DebugLevel, "filtered")
+	recorder.Log(log.InfoLevel, "started", "port", 80)
+	child := recorder.With("module", "db")
+	child.Log(log.ErrorLevel, "query failed", "table", "users", "odd")
+
+	assert.Equal(t, 2, recorder.Len())
+	assert.Equal(t, []string{"query failed"}, recorder.FilterLevel(log.ErrorLevel).Messages())
+	assert.Len(t, recorder.FilterMessage("started").FilterField("port", 80), 1)
+	assert.Empty(t, recorder.FilterField("port", "80"))
+
+	entry := recorder.FilterField("module", "db")[0]
+	assert.Equal(t, []interface{}{"module", "db", "table", "users", "odd"}, entry.Keyvals)
+	value, ok := entry.Field(log.BadKey)
+	assert.True(t, ok)
+	assert.Equal(t, "odd", value)
+	assert.True(t, strings.HasPrefix(entry.Caller, "recorder_test.go:"))
+
+	recorder.Reset()
+	assert.Equal(t, 0, recorder.Len())
+}
+
+func TestSwap(t *testing.T) {
+	previous := log.GetLogger()
+	t.Run("group", func(t *testing.T) {
+		for i := 0; i < 4; i++ {
+			i := i
+			t.Run(fmt.Sprint(i), func(t *testing.T) {
+				t.Parallel()
+				recorder := logtest.Swap(t)
+				log.Infow("parallel", "index", i)
+				log.InfoContext(context.TODO(), "context")
+				log.Debugf("debug %d", i) // 替换期间记录全部级别的日志
+
+				// 每个测试只记录自己的日志
+				assert.Equal(t, []string{"parallel", "context", fmt.Sprintf("debug %d", i)}, recorder.Entries().Messages())
+				assert.Len(t, recorder.FilterField("index", i), 1)
+				assert.True(t, strings.HasPrefix(recorder.Entries()[0].Caller, "recorder_test.go:"))
+			})
+		}
+	})
+	assert.Same(t, previous, log.GetLogger())
+}
@@ -0,0 +1,57 @@
+package logtest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/wolfbolin/bolbox/pkg/log"
+)
+
+// swapping 保证同一时刻只有一个测试替换全局日志记录器
+var swapping = struct {
+	sync.Mutex
+	cond   *sync.Cond
+	holder string // 当前替换全局日志记录器的测试名称
+}{}
+
+func init() {
+	swapping.cond = sync.NewCond(&swapping.Mutex)
+}
+
+// Swap 在测试期间将全局日志记录器替换为新的 Recorder，测试结束时恢复原有的日志记录器与日志级别。
+// 调用 Swap 的测试之间互斥地持有全局日志记录器，即使使用 t.Parallel() 也会依次执行，因此不会记录到
+// 其他调用 Swap 的测试的日志；持有期间未调用 Swap 的并行测试通过全局函数输出的日志仍会被记录。
+// 替换期间在同一测试或其子测试中再次调用 Swap 时测试失败。
+//
+// 并行测试必须先调用 t.Parallel() 再调用 Swap：在 Swap 之后调用 t.Parallel() 的测试会在暂停期间
+// 持有全局日志记录器，之后顺序执行的测试调用 Swap 时将永久阻塞。
+func Swap(t testing.TB) *Recorder {
+	t.Helper()
+	name := t.Name()
+	swapping.Lock()
+	for swapping.holder != "" {
+		if holder := swapping.holder; name == holder || strings.HasPrefix(name, holder+"/") {
+			swapping.Unlock()
+			t.Fatalf("Global logger has already been swapped by test[%s]", holder)
+			return nil
+		}
+		swapping.cond.Wait()
+	}
+	swapping.holder = name
+	swapping.Unlock()
+
+	recorder := New()
+	previous, level := log.GetLogger(), log.GetLevel()
+	log.SetLogger(recorder)
+	log.SetLevel(log.DebugLevel)
+	t.Cleanup(func() {
+		log.SetLogger(previous)
+		log.SetLevel(level)
+		swapping.Lock()
+		swapping.holder = ""
+		swapping.cond.Broadcast()
+		swapping.Unlock()
+	})
+	return recorder
+}
@@ -0,0 +1,80 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+)
+
+// ErrPanic is the cause of the panics raised by the Panic functions, use errors.Is to detect them
+var ErrPanic = errors.New("Log panic.")
+
+// PanicError is the value of the panics raised by the Panic functions after the log is printed.
+// It carries the message and keyvals of the log, and a stack trace printed with %+v.
+type PanicError struct {
+	Msg     string
+	Keyvals []interface{}
+	err     error
+}
+
+// newPanicError creates the panic value of the log
+func newPanicError(msg string, keyvals []interface{}) *PanicError {
+	text := msg
+	if len(keyvals) != 0 {
+		pairs := make([]string, 0, (len(keyvals)+1)/2)
+		for i := 0; i < len(keyvals); i += 2 {
+			if i+1 < len(keyvals) {
+				pairs = append(pairs, fmt.Sprintf("%v=%v", keyvals[i], keyvals[i+1]))
+			} else {
+				pairs = append(pairs, fmt.Sprintf("%v", keyvals[i]))
+			}
+		}
+		text = msg + " " + strings.Join(pairs, " ")
+	}
+	return &PanicError{
+		Msg:     msg,
+		Keyvals: keyvals,
+		err:     errors.Wrap(ErrPanic, text),
+	}
+}
+
+func (p *PanicError) Error() string {
+	return p.err.Error()
+}
+
+func (p *PanicError) Unwrap() error {
+	return p.err
+}
+
+// Format formats the error like the errors package, %+v prints the stack trace
+func (p *PanicError) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, fmt.FormatString(s, verb), p.err)
+}
+
+// exitFunc is called by the Fatal functions after the log is printed, nil means defaultExit
+var exitFunc atomic.Pointer[func(code int)]
+
+// SetExitFunc replaces the function called with code 1 by the Fatal functions after the log is printed.
+// It is meant for tests and for releasing resources before exiting. If the function returns,
+// the Fatal functions return as well. nil restores the default function, which closes the global logger
+// to flush buffered logs and then calls os.Exit.
+func SetExitFunc(exit func(code int)) {
+	if exit == nil {
+		exitFunc.Store(nil)
+		return
+	}
+	exitFunc.Store(&exit)
+}
+
+// exit calls the exit function
+func exit(code int) {
+	if exit := exitFunc.Load(); exit != nil {
+		(*exit)(code)
+		return
+	}
+	_ = Close()
+	os.Exit(code)
+}
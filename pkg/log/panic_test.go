@@ -0,0 +1,55 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+)
+
+func TestPanic(t *testing.T) {
+	recorder := &recordLogger{}
+	SetLogger(recorder)
+	defer SetLogger(&DefaultLogger{})
+
+	value := func(f func()) (recovered any) {
+		defer func() { recovered = recover() }()
+		f()
+		return nil
+	}
+
+	recovered := value(func() { Panicw("boom", "user", 1) })
+	err, ok := recovered.(*PanicError)
+	assert.True(t, ok)
+	assert.Equal(t, "boom", err.Msg)
+	assert.Equal(t, []interface{}{"user", 1}, err.Keyvals)
+	assert.True(t, errors.Is(err, ErrPanic))
+	assert.Equal(t, "boom user=1: Log panic.", err.Error())
+	assert.Contains(t, fmt.Sprintf("%+v", err), "panic_test.go")
+	assert.Equal(t, [][]interface{}{{"user", 1}}, recorder.keyvals)
+
+	// 默认日志实现与被限流的日志同样抛出 panic
+	SetLogger(&DefaultLogger{})
+	SetLevel(FatalLevel)
+	defer SetLevel(DebugLevel)
+	assert.IsType(t, &PanicError{}, value(func() { Panicf("boom %d", 2) }))
+	assert.IsType(t, &PanicError{}, value(func() { PanicContext(context.TODO(), "boom") }))
+}
+
+func TestFatal(t *testing.T) {
+	recorder := &recordLogger{}
+	SetLogger(recorder)
+	defer SetLogger(&DefaultLogger{})
+	codes := make([]int, 0)
+	SetExitFunc(func(code int) { codes = append(codes, code) })
+	defer SetExitFunc(nil)
+
+	Fatal("fatal")
+	Fatalw("fatal", "user", 1)
+	FatalContext(WithFields(context.TODO(), "request", "r1"), "fatal")
+	assert.Equal(t, []int{1, 1, 1}, codes)
+	assert.Equal(t, [][]interface{}{nil, {"user", 1}, {"request", "r1"}}, recorder.keyvals)
+}
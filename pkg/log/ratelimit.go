@@ -0,0 +1,127 @@
+package log
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SuppressedKey is the key of the number of suppressed logs in the summary logs of rate limiting
+const SuppressedKey = "suppressed"
+
+// RateLimit limits the number of logs printed from the same callsite.
+// At most Burst logs are printed from each callsite in every Interval, the rest are suppressed,
+// and a summary like "12 messages suppressed" is printed at the end of the interval.
+type RateLimit struct {
+	Interval time.Duration
+	Burst    int
+}
+
+// rateLimiter is the rate limiter of logs printed through this package, nil means no limit
+var rateLimiter atomic.Pointer[limiter]
+
+// SetRateLimit limits the number of logs printed through this package, the context functions and named loggers.
+// Logs are counted by callsite, so the logs of a noisy loop are limited regardless of their formatted messages.
+// It works with every logging component, and with the records of log/slog handled by SlogHandler,
+// which are counted by the PC of the record. PanicLevel and FatalLevel logs are never suppressed.
+// A limit whose Interval or Burst is not greater than 0 removes the rate limiting.
+func SetRateLimit(limit RateLimit) {
+	if limit.Interval <= 0 || limit.Burst <= 0 {
+		rateLimiter.Store(nil)
+		return
+	}
+	rateLimiter.Store(&limiter{
+		RateLimit: limit,
+		windows:   make(map[uintptr]*rateWindow),
+	})
+}
+
+// limiter counts the logs of each callsite in fixed windows
+type limiter struct {
+	RateLimit
+	lock    sync.Mutex
+	windows map[uintptr]*rateWindow
+}
+
+// rateWindow is the counting window of a callsite
+type rateWindow struct {
+	start      time.Time
+	count      int
+	suppressed int
+	level      Level  // the highest level of suppressed logs
+	caller     string // file:line of the callsite, resolved once logs are suppressed
+	timer      *time.Timer
+}
+
+// rateLimited reports whether a log of the level is suppressed by the rate limiting.
+// skip is the number of frames between the caller of rateLimited and the callsite.
+func rateLimited(level Level, skip int) bool {
+	if rateLimiter.Load() == nil || level >= PanicLevel {
+		return false
+	}
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return false
+	}
+	return rateLimitedAt(level, pcs[0])
+}
+
+// rateLimitedAt reports whether a log of the level from the callsite pc is suppressed by the rate limiting,
+// logs without a callsite are never suppressed
+func rateLimitedAt(level Level, pc uintptr) bool {
+	limiter := rateLimiter.Load()
+	if limiter == nil || level >= PanicLevel || pc == 0 {
+		return false
+	}
+	return !limiter.allow(pc, level)
+}
+
+// allow counts a log from the callsite pc, and reports whether the log can be printed
+func (l *limiter) allow(pc uintptr, level Level) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	now := time.Now()
+	window, ok := l.windows[pc]
+	if !ok {
+		window = &rateWindow{start: now}
+		l.windows[pc] = window
+	}
+	if window.timer != nil {
+		// The summary of this window is pending
+		window.suppressed += 1
+		window.level = max(window.level, level)
+		return false
+	}
+	if now.Sub(window.start) >= l.Interval {
+		window.start, window.count = now, 0
+	}
+	window.count += 1
+	if window.count <= l.Burst {
+		return true
+	}
+
+	window.suppressed, window.level, window.caller = 1, level, callsite(pc)
+	window.timer = time.AfterFunc(window.start.Add(l.Interval).Sub(now), func() {
+		l.summarize(pc)
+	})
+	return false
+}
+
+// summarize prints the summary of the suppressed logs from the callsite pc, and starts a new window
+func (l *limiter) summarize(pc uintptr) {
+	l.lock.Lock()
+	window := l.windows[pc]
+	delete(l.windows, pc)
+	l.lock.Unlock()
+
+	getLogger().Log(window.level, fmt.Sprintf("%d messages suppressed", window.suppressed),
+		SuppressedKey, window.suppressed, "caller", window.caller)
+}
+
+// callsite returns file:line of the program counter returned by runtime.Callers
+func callsite(pc uintptr) string {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+}
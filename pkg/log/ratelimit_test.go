@@ -0,0 +1,84 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type messageLogger struct {
+	lock     sync.Mutex
+	messages []string
+	keyvals  [][]interface{}
+}
+
+func (m *messageLogger) Log(level Level, msg string, keyvals ...interface{}) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.messages = append(m.messages, msg)
+	m.keyvals = append(m.keyvals, keyvals)
+}
+
+func (m *messageLogger) Enabled(level Level) bool {
+	return true
+}
+
+func (m *messageLogger) snapshot() ([]string, [][]interface{}) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return append([]string{}, m.messages...), append([][]interface{}{}, m.keyvals...)
+}
+
+func TestRateLimit(t *testing.T) {
+	recorder := &messageLogger{}
+	SetLogger(recorder)
+	defer SetLogger(&DefaultLogger{})
+	SetRateLimit(RateLimit{Interval: 100 * time.Millisecond, Burst: 3})
+	defer SetRateLimit(RateLimit{})
+
+	for i := 0; i < 10; i++ {
+		Warnf("retry %d", i) // 相同调用位置的日志被限流，与格式化后的消息无关
+		Infof("other %d", i)
+	}
+	Named("db").Log(ErrorLevel, "named")
+	messages, _ := recorder.snapshot()
+	assert.Equal(t, []string{"retry 0", "other 0", "retry 1", "other 1", "retry 2", "other 2", "named"}, messages)
+
+	assert.Eventually(t, func() bool {
+		messages, _ := recorder.snapshot()
+		return len(messages) == 9
+	}, time.Second, 5*time.Millisecond)
+	messages, keyvals := recorder.snapshot()
+	assert.ElementsMatch(t, []string{"7 messages suppressed", "7 messages suppressed"}, messages[7:])
+	assert.Equal(t, SuppressedKey, keyvals[7][0])
+	assert.Equal(t, 7, keyvals[7][1])
+	assert.Contains(t, keyvals[7][3], "ratelimit_test.go:")
+
+	// 新的窗口重新计数
+	Warnf("retry again")
+	messages, _ = recorder.snapshot()
+	assert.Equal(t, "retry again", messages[len(messages)-1])
+}
+
+func TestRateLimitSlog(t *testing.T) {
+	recorder := &messageLogger{}
+	SetRateLimit(RateLimit{Interval: time.Hour, Burst: 2})
+	defer SetRateLimit(RateLimit{})
+
+	// slog 的日志按记录的调用位置限流
+	logger := slog.New(NewSlogHandler(recorder))
+	for i := 0; i < 5; i++ {
+		logger.Warn("slog retry")
+	}
+	logger.Info("slog other")
+	// 没有调用位置的记录不被限流
+	for i := 0; i < 3; i++ {
+		_ = logger.Handler().Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "no pc", 0))
+	}
+	messages, _ := recorder.snapshot()
+	assert.Equal(t, []string{"slog retry", "slog retry", "slog other", "no pc", "no pc", "no pc"}, messages)
+}
@@ -0,0 +1,201 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// slog levels used for PanicLevel and FatalLevel, which have no counterpart in log/slog
+const (
+	SlogLevelPanic = slog.LevelError + 4
+	SlogLevelFatal = slog.LevelError + 8
+)
+
+// SlogHandler is an slog.Handler that routes records of log/slog into a Logger.
+// Attributes in groups are flattened into keys joined by dots, like "group.key".
+type SlogHandler struct {
+	logger  Logger
+	keyvals []interface{}
+	prefix  string
+}
+
+var _ slog.Handler = (*SlogHandler)(nil)
+
+// NewSlogHandler returns an slog.Handler that writes records into logger.
+// If logger is nil, records are written into the logger carried by the context of the record,
+// or the global logger if there is none.
+// Do not route the global logger back into the same slog handler, which would loop forever.
+func NewSlogHandler(logger Logger) *SlogHandler {
+	return &SlogHandler{
+		logger: logger,
+	}
+}
+
+// Enabled reports whether the target logger handles records at the given level, the global level is considered
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	logger := enabledFor(h.target(ctx), FromSlogLevel(level))
+	return logger != nil && logger.Enabled(FromSlogLevel(level))
+}
+
+// Handle writes the record into the target logger.
+// Records are rate limited by their PC like the logs of this package, records without a PC are never suppressed.
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	level := FromSlogLevel(record.Level)
+	logger := enabledFor(h.target(ctx), level)
+	if logger == nil || rateLimitedAt(level, record.PC) {
+		return nil
+	}
+	keyvals := make([]interface{}, 0, len(h.keyvals)+2*record.NumAttrs())
+	keyvals = append(keyvals, h.keyvals...)
+	record.Attrs(func(attr slog.Attr) bool {
+		keyvals = appendAttr(keyvals, h.prefix, attr)
+		return true
+	})
+	logger.Log(level, record.Message, keyvals...)
+	return nil
+}
+
+// WithAttrs returns a handler that appends attrs to every record
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	child := *h
+	child.keyvals = append([]interface{}{}, h.keyvals...)
+	for _, attr := range attrs {
+		child.keyvals = appendAttr(child.keyvals, h.prefix, attr)
+	}
+	return &child
+}
+
+// WithGroup returns a handler that puts the attributes of subsequent records into the group
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	child := *h
+	child.prefix = h.prefix + name + "."
+	return &child
+}
+
+func (h *SlogHandler) target(ctx context.Context) Logger {
+	if h.logger != nil {
+		return h.logger
+	}
+	return FromContext(ctx)
+}
+
+// appendAttr appends the resolved attribute to keyvals, attributes in groups are flattened
+func appendAttr(keyvals []interface{}, prefix string, attr slog.Attr) []interface{} {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return keyvals
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix = prefix + attr.Key + "."
+		}
+		for _, groupAttr := range attr.Value.Group() {
+			keyvals = appendAttr(keyvals, groupPrefix, groupAttr)
+		}
+		return keyvals
+	}
+	return append(keyvals, prefix+attr.Key, attr.Value.Any())
+}
+
+// slogLogger is a Logger backed by an slog.Handler
+type slogLogger struct {
+	handler slog.Handler
+	name    string // the name of the named logger whose level the logger follows
+	skip    int    // number of frames between Log and the source, 1 for the caller of the global log functions
+}
+
+var _ FieldLogger = (*slogLogger)(nil)
+
+// NewSlogLogger returns a Logger that writes logs into the slog handler.
+// PanicLevel and FatalLevel are written at SlogLevelPanic and SlogLevelFatal.
+func NewSlogLogger(handler slog.Handler) Logger {
+	return NewSlogLoggerSkip(handler, 0)
+}
+
+// NewSlogLoggerSkip is like NewSlogLogger, callSkip is the number of extra frames to skip when the global log
+// functions are wrapped, like DefaultLogger.CallSkip. Negative values are treated as 0.
+func NewSlogLoggerSkip(handler slog.Handler, callSkip int) Logger {
+	return &slogLogger{
+		handler: handler,
+		skip:    1 + max(callSkip, 0),
+	}
+}
+
+// NewDirectSlogLogger is like NewSlogLogger for code that calls the Log method of the logger directly
+// instead of through the global log functions, the caller of Log is recorded as the source.
+func NewDirectSlogLogger(handler slog.Handler) Logger {
+	return &slogLogger{handler: handler}
+}
+
+// Log writes the log into the slog handler, the caller of the global log function,
+// or the caller of Log for NewDirectSlogLogger, is recorded as the source
+func (s *slogLogger) Log(level Level, msg string, keyvals ...interface{}) {
+	slogLevel := ToSlogLevel(level)
+	ctx := context.Background()
+	if !LevelEnabled(s.name, level) || !s.handler.Enabled(ctx, slogLevel) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(2+s.skip, pcs[:]) // skip runtime.Callers and Log
+	record := slog.NewRecord(time.Now(), slogLevel, msg, pcs[0])
+	record.Add(keyvals...)
+	_ = s.handler.Handle(ctx, record)
+}
+
+// Enabled reports whether the level passes the level set by SetLevel and the slog handler handles it
+func (s *slogLogger) Enabled(level Level) bool {
+	return LevelEnabled(s.name, level) && s.handler.Enabled(context.Background(), ToSlogLevel(level))
+}
+
+// With returns a child logger that appends keyvals to every log
+func (s *slogLogger) With(keyvals ...interface{}) Logger {
+	record := slog.Record{}
+	record.Add(keyvals...)
+	attrs := make([]slog.Attr, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+	return &slogLogger{handler: s.handler.WithAttrs(attrs), name: LoggerName(s.name, keyvals...), skip: s.skip}
+}
+
+// ToSlogLevel converts the log level to the slog level
+func ToSlogLevel(level Level) slog.Level {
+	switch level {
+	case DebugLevel:
+		return slog.LevelDebug
+	case InfoLevel:
+		return slog.LevelInfo
+	case WarnLevel:
+		return slog.LevelWarn
+	case ErrorLevel:
+		return slog.LevelError
+	case PanicLevel:
+		return SlogLevelPanic
+	case FatalLevel:
+		return SlogLevelFatal
+	default:
+		return slog.LevelDebug
+	}
+}
+
+// FromSlogLevel converts the slog level to the log level.
+// Levels between two slog levels are rounded down, levels above LevelError never panic or exit.
+func FromSlogLevel(level slog.Level) Level {
+	switch {
+	case level < slog.LevelInfo:
+		return DebugLevel
+	case level < slog.LevelWarn:
+		return InfoLevel
+	case level < slog.LevelError:
+		return WarnLevel
+	default:
+		return ErrorLevel
+	}
+}
@@ -0,0 +1,121 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type levelLogger struct {
+	recordLogger
+	levels []Level
+	msgs   []string
+}
+
+func (l *levelLogger) Log(level Level, msg string, keyvals ...interface{}) {
+	l.levels = append(l.levels, level)
+	l.msgs = append(l.msgs, msg)
+	l.recordLogger.Log(level, msg, keyvals...)
+}
+
+func (l *levelLogger) Enabled(level Level) bool {
+	return level >= InfoLevel
+}
+
+func TestSlogHandler(t *testing.T) {
+	recorder := &levelLogger{}
+	logger := slog.New(NewSlogHandler(recorder))
+
+	logger.Debug("hidden")
+	logger.With("service", "api").WithGroup("req").With("id", "r1").
+		Info("handled", "status", 200, slog.Group("user", "name", "bob"), slog.Group("empty"))
+	logger.Log(context.TODO(), slog.LevelError+2, "custom")
+
+	assert.Equal(t, []Level{InfoLevel, ErrorLevel}, recorder.levels)
+	assert.Equal(t, []string{"handled", "custom"}, recorder.msgs)
+	assert.Equal(t, []interface{}{
+		"service", "api", "req.id", "r1", "req.status", int64(200), "req.user.name", "bob",
+	}, recorder.keyvals[0])
+}
+
+func TestSlogHandlerContext(t *testing.T) {
+	recorder := &recordLogger{}
+	ctx := WithContext(context.TODO(), recorder)
+	slog.New(NewSlogHandler(nil)).InfoContext(ctx, "scoped", "key", "value")
+	assert.Equal(t, [][]interface{}{{"key", "value"}}, recorder.keyvals)
+}
+
+func TestSlogLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := slog.NewTextHandler(buf, &slog.HandlerOptions{
+		AddSource: true,
+		Level:     slog.LevelInfo,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			if attr.Key == slog.SourceKey {
+				source := attr.Value.Any().(*slog.Source)
+				return slog.String(slog.SourceKey, source.Function[strings.LastIndex(source.Function, ".")+1:])
+			}
+			return attr
+		},
+	})
+	SetLogger(NewSlogLogger(handler))
+	defer SetLogger(&DefaultLogger{})
+
+	assert.False(t, Enabled(DebugLevel))
+	Debug("hidden")
+	Infow("hello", "user", 1)
+	WarnContext(WithFields(context.TODO(), "request", "r1"), "child", "odd")
+	Errorw("panic level", "level", "x")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, []string{
+		`level=INFO source=TestSlogLogger msg=hello user=1`,
+		`level=WARN source=TestSlogLogger msg=child request=r1 !BADKEY=odd`,
+		`level=ERROR source=TestSlogLogger msg="panic level" level=x`,
+	}, lines)
+	assert.Equal(t, SlogLevelFatal, ToSlogLevel(FatalLevel))
+}
+
+// logThrough 包装日志记录器的辅助函数，用于验证 callSkip
+func logThrough(logger Logger, msg string) {
+	logger.Log(InfoLevel, msg)
+}
+
+func TestSlogLoggerSkip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := slog.NewTextHandler(buf, &slog.HandlerOptions{
+		AddSource: true,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			if attr.Key == slog.SourceKey {
+				source := attr.Value.Any().(*slog.Source)
+				return slog.String(slog.SourceKey, source.Function[strings.LastIndex(source.Function, ".")+1:])
+			}
+			return attr
+		},
+	})
+
+	// 直接调用时记录 Log 的调用方，被包装一层时与全局函数相同，额外的包装层通过 callSkip 跳过
+	WithLogger(NewDirectSlogLogger(handler), "user", 1).Log(InfoLevel, "direct")
+	logThrough(NewSlogLogger(handler), "wrapped")
+	func() {
+		logThrough(NewSlogLoggerSkip(handler, 1), "skipped")
+	}()
+	logThrough(NewSlogLoggerSkip(handler, -1), "negative")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, []string{
+		`level=INFO source=TestSlogLoggerSkip msg=direct user=1`,
+		`level=INFO source=TestSlogLoggerSkip msg=wrapped`,
+		`level=INFO source=TestSlogLoggerSkip msg=skipped`,
+		`level=INFO source=TestSlogLoggerSkip msg=negative`,
+	}, lines)
+}
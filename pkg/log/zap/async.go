@@ -0,0 +1,197 @@
+package zap
+
+import (
+	"bufio"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+)
+
+// OverflowPolicy 异步写入的缓冲区已满时的处理策略
+type OverflowPolicy int
+
+const (
+	// DropOnFull 丢弃新写入的日志并计数
+	DropOnFull OverflowPolicy = iota
+	// BlockOnFull 阻塞写入直至缓冲区有空位
+	BlockOnFull
+)
+
+// 异步写入的默认选项
+const (
+	DefaultAsyncBufferSize    = 4096
+	DefaultAsyncFlushInterval = time.Second
+	DefaultAsyncCloseTimeout  = 5 * time.Second
+)
+
+// Async 异步写入选项，日志先写入有界缓冲区，由后台协程批量写入输出
+type Async struct {
+	BufferSize    int            // 缓冲区可容纳的日志条数，不大于 0 时使用 DefaultAsyncBufferSize
+	FlushInterval time.Duration  // 定期将批量写入的内容刷新到输出的间隔，不大于 0 时使用 DefaultAsyncFlushInterval
+	Overflow      OverflowPolicy // 缓冲区已满时的处理策略
+	CloseTimeout  time.Duration  // Close 等待缓冲区写入输出的最长时间，不大于 0 时使用 DefaultAsyncCloseTimeout
+}
+
+// AsyncStats 异步写入的统计
+type AsyncStats struct {
+	Written uint64 // 已写入输出的日志条数
+	Dropped uint64 // 因缓冲区已满而被丢弃的日志条数
+	Queued  int    // 缓冲区中等待写入的日志条数
+}
+
+// AsyncWriter 异步写入器，Sync 等待缓冲区中的日志全部写入输出，Close 后的日志直接写入输出
+type AsyncWriter struct {
+	writer       zapcore.WriteSyncer
+	overflow     OverflowPolicy
+	closeTimeout time.Duration
+
+	lock    sync.RWMutex
+	closed  bool
+	queue   chan []byte
+	flushes chan chan error
+	stop    chan struct{}
+	done    chan struct{}
+
+	written atomic.Uint64
+	dropped atomic.Uint64
+}
+
+var _ zapcore.WriteSyncer = (*AsyncWriter)(nil)
+
+// NewAsyncWriter 创建异步写入器并启动后台写入协程，使用完毕后需调用 Close
+func NewAsyncWriter(writer zapcore.WriteSyncer, option Async) *AsyncWriter {
+	if option.BufferSize <= 0 {
+		option.BufferSize = DefaultAsyncBufferSize
+	}
+	if option.FlushInterval <= 0 {
+		option.FlushInterval = DefaultAsyncFlushInterval
+	}
+	if option.CloseTimeout <= 0 {
+		option.CloseTimeout = DefaultAsyncCloseTimeout
+	}
+	w := &AsyncWriter{
+		writer:       writer,
+		overflow:     option.Overflow,
+		closeTimeout: option.CloseTimeout,
+		queue:        make(chan []byte, option.BufferSize),
+		flushes:      make(chan chan error),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	go w.loop(option.FlushInterval)
+	return w
+}
+
+// Write 将日志复制到缓冲区，缓冲区已满时按照处理策略丢弃或阻塞
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+	if w.closed {
+		return w.writer.Write(p)
+	}
+	entry := append([]byte(nil), p...) // zap 会复用写入的缓冲区
+	if w.overflow == BlockOnFull {
+		w.queue <- entry
+		return len(p), nil
+	}
+	select {
+	case w.queue <- entry:
+	default:
+		w.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// Sync 将缓冲区中的日志全部写入输出并同步输出
+func (w *AsyncWriter) Sync() error {
+	reply := make(chan error, 1)
+	select {
+	case w.flushes <- reply:
+		return <-reply
+	case <-w.done:
+		return w.writer.Sync()
+	}
+}
+
+// Close 将缓冲区中的日志全部写入输出并停止后台写入协程，最多等待 CloseTimeout，输出的同步错误被忽略
+func (w *AsyncWriter) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), w.closeTimeout)
+	defer cancel()
+	return w.CloseContext(ctx)
+}
+
+// CloseContext 与 Close 相同，但等待缓冲区写入输出直至 ctx 结束。
+// 输出阻塞导致 ctx 结束时返回错误，之后的日志直接写入输出，缓冲区中剩余的日志由后台写入协程继续写入，
+// 文件输出在后台写入协程退出前保持打开，进程在此之前退出时剩余的日志被丢弃
+func (w *AsyncWriter) CloseContext(ctx context.Context) error {
+	w.lock.Lock()
+	if w.closed {
+		w.lock.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.lock.Unlock()
+	close(w.stop)
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrapf(ctx.Err(), "Async writer has %d queued logs when closing", len(w.queue))
+	}
+}
+
+// Stats 返回异步写入的统计
+func (w *AsyncWriter) Stats() AsyncStats {
+	return AsyncStats{
+		Written: w.written.Load(),
+		Dropped: w.dropped.Load(),
+		Queued:  len(w.queue),
+	}
+}
+
+// loop 将缓冲区中的日志批量写入输出，并定期刷新
+func (w *AsyncWriter) loop(interval time.Duration) {
+	defer close(w.done)
+	buffered := bufio.NewWriterSize(w.writer, 256*1024)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	write := func(entry []byte) {
+		if _, err := buffered.Write(entry); err == nil {
+			w.written.Add(1)
+		}
+	}
+	drain := func() error {
+		for {
+			select {
+			case entry := <-w.queue:
+				write(entry)
+			default:
+				return buffered.Flush()
+			}
+		}
+	}
+	for {
+		select {
+		case entry := <-w.queue:
+			write(entry)
+		case <-ticker.C:
+			_ = buffered.Flush()
+		case reply := <-w.flushes:
+			err := drain()
+			if syncErr := w.writer.Sync(); err == nil {
+				err = syncErr
+			}
+			reply <- err
+		case <-w.stop:
+			_ = drain()
+			_ = w.writer.Sync()
+			return
+		}
+	}
+}
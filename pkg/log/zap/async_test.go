@@ -0,0 +1,185 @@
+package zap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wolfbolin/bolbox/pkg/log"
+)
+
+// gateWriter 在 release 关闭前阻塞写入的输出
+type gateWriter struct {
+	lock    sync.Mutex
+	buf     bytes.Buffer
+	entered chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{entered: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (g *gateWriter) Write(p []byte) (int, error) {
+	g.once.Do(func() { close(g.entered) })
+	<-g.release
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.buf.Write(p)
+}
+
+func (g *gateWriter) Sync() error {
+	return nil
+}
+
+func (g *gateWriter) String() string {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.buf.String()
+}
+
+func TestAsyncWriterDrop(t *testing.T) {
+	gate := newGateWriter()
+	writer := NewAsyncWriter(gate, Async{BufferSize: 2, FlushInterval: time.Hour})
+	_, _ = writer.Write([]byte("a\n"))
+	synced := make(chan error)
+	go func() { synced <- writer.Sync() }()
+	<-gate.entered // 后台协程阻塞在写入输出
+
+	for _, entry := range []string{"b\n", "c\n", "d\n", "e\n", "f\n"} {
+		n, err := writer.Write([]byte(entry))
+		assert.Equal(t, 2, n)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, AsyncStats{Written: 1, Dropped: 3, Queued: 2}, writer.Stats())
+
+	close(gate.release)
+	assert.Nil(t, <-synced)
+	assert.Nil(t, writer.Close())
+	assert.Equal(t, "a\nb\nc\n", gate.String())
+	assert.Equal(t, AsyncStats{Written: 3, Dropped: 3}, writer.Stats())
+
+	// 关闭后直接写入输出
+	_, _ = writer.Write([]byte("g\n"))
+	assert.Nil(t, writer.Sync())
+	assert.Nil(t, writer.Close())
+	assert.Equal(t, "a\nb\nc\ng\n", gate.String())
+}
+
+func TestAsyncWriterBlock(t *testing.T) {
+	gate := newGateWriter()
+	writer := NewAsyncWriter(gate, Async{BufferSize: 1, FlushInterval: time.Millisecond, Overflow: BlockOnFull})
+	_, _ = writer.Write([]byte("a\n"))
+	<-gate.entered // 定期刷新阻塞在写入输出
+
+	_, _ = writer.Write([]byte("b\n"))
+	written := make(chan struct{})
+	go func() {
+		_, _ = writer.Write([]byte("c\n"))
+		close(written)
+	}()
+	select {
+	case <-written:
+		t.Fatalf("Write should block when the buffer is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(gate.release)
+	<-written
+	assert.Nil(t, writer.Close())
+	assert.Equal(t, "a\nb\nc\n", gate.String())
+	assert.Equal(t, uint64(0), writer.Stats().Dropped)
+}
+
+func TestAsyncLogger(t *testing.T) {
+	var buf syncBuffer
+	option := NewDefaultOption("", log.InfoLevel)
+	option.Sinks = []Sink{{Type: WriterSink, Writer: &buf}}
+	option.Async = &Async{FlushInterval: 5 * time.Millisecond}
+	logger := NewZapLogger(option)
+
+	logger.Log(log.InfoLevel, "flushed periodically")
+	assert.Eventually(t, func() bool {
+		return strings.Contains(buf.String(), "flushed periodically")
+	}, time.Second, time.Millisecond)
+
+	child := logger.With("k", "v")
+	child.Log(log.InfoLevel, "flushed on close")
+	assert.Nil(t, logger.Close())
+	assert.Contains(t, buf.String(), "flushed on close")
+	assert.Equal(t, uint64(2), logger.AsyncStats().Written)
+}
+
+type syncBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.buf.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.buf.String()
+}
+
+func TestAsyncWriterCloseTimeout(t *testing.T) {
+	gate := newGateWriter()
+	writer := NewAsyncWriter(gate, Async{FlushInterval: time.Millisecond, CloseTimeout: 20 * time.Millisecond})
+	_, _ = writer.Write([]byte("a\n"))
+	<-gate.entered // 输出阻塞
+
+	// 输出阻塞时 Close 不会无限等待
+	begin := time.Now()
+	err := writer.Close()
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, time.Since(begin), time.Second)
+	assert.Nil(t, writer.Close())
+
+	close(gate.release)
+	assert.Eventually(t, func() bool {
+		return gate.String() == "a\n"
+	}, time.Second, 5*time.Millisecond)
+	assert.Nil(t, writer.CloseContext(context.Background()))
+}
+
+type closeCounter struct {
+	closes atomic.Int32
+}
+
+func (c *closeCounter) Close() error {
+	c.closes.Add(1)
+	return nil
+}
+
+func TestAsyncFileCloser(t *testing.T) {
+	gate := newGateWriter()
+	writer := NewAsyncWriter(gate, Async{FlushInterval: time.Millisecond, CloseTimeout: 20 * time.Millisecond})
+	file := &closeCounter{}
+	closer := &asyncFileCloser{async: writer, file: file}
+	_, _ = writer.Write([]byte("a\n"))
+	<-gate.entered
+
+	// 后台写入协程仍在写入时不关闭文件
+	assert.NotNil(t, writer.Close())
+	assert.NotNil(t, closer.Close())
+	assert.Equal(t, int32(0), file.closes.Load())
+
+	close(gate.release)
+	assert.Eventually(t, func() bool {
+		return closer.Close() == nil
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(1), file.closes.Load())
+}
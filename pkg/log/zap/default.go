@@ -1,60 +1,56 @@
 package zap
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-// NewZapLogger 根据给定的日志选项创建日志记录器
+// NewZapLogger 根据给定的日志选项创建日志记录器，各个输出通过 zapcore.NewTee 组合，创建失败的输出被忽略。
+// 使用文件、syslog 或异步写入时，不再使用日志记录器后需调用 Close 释放资源
 func NewZapLogger(option *Option) *Logger {
-	var writerSyncer []zapcore.WriteSyncer
-	if option.FilePath != "" {
-		writerSyncer = append(writerSyncer, zapcore.AddSync(newLumberjackSyncer(option)))
+	var cores []zapcore.Core
+	var closers []io.Closer
+	for _, sink := range option.sinks() {
+		core, err := newSinkCore(option, sink, &closers)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Create %s log sink failed, the sink is ignored. %v\n", sink.Type, err)
+			continue
+		}
+		cores = append(cores, core)
 	}
-
-	if option.ConsoleLogger {
-		writerSyncer = append(writerSyncer, zapcore.AddSync(os.Stdout))
+	core := zapcore.NewTee(cores...)
+	if sampling := option.Sampling; sampling != nil && sampling.Tick > 0 {
+		core = zapcore.NewSamplerWithOptions(core, sampling.Tick, sampling.First, sampling.Thereafter)
 	}
 
-	core := zapcore.NewCore(
-		newConsoleEncoder(option),
-		zapcore.NewMultiWriteSyncer(writerSyncer...),
-		option.LogLevel,
-	)
-
 	zapOptions := []zap.Option{zap.AddCaller(), zap.AddCallerSkip(option.CallSkip)}
 	if option.Stack {
 		zapOptions = append(zapOptions, zap.AddStacktrace(zap.ErrorLevel))
 	}
 
-	return NewLogger(zap.New(core, zapOptions...))
-}
-
-func newLumberjackSyncer(option *Option) *lumberjack.Logger {
-	return &lumberjack.Logger{
-		Filename:   option.FilePath,
-		MaxSize:    option.MaxSize,
-		MaxBackups: option.MaxBackups,
-		MaxAge:     option.MaxAge,
-		Compress:   true,
-		LocalTime:  true,
-	}
+	logger := NewLogger(zap.New(core, zapOptions...))
+	logger.closers = closers
+	return logger
 }
 
-func newConsoleEncoder(option *Option) zapcore.Encoder {
+// newEncoder 根据日志选项创建指定编码格式的编码器，console 表示编码器是否用于控制台
+func newEncoder(option *Option, encoding Encoding, console bool) zapcore.Encoder {
+	keys := option.Keys.withDefaults()
 	encoderCfg := zapcore.EncoderConfig{
-		TimeKey:          "time",
-		LevelKey:         "level",
-		NameKey:          "logger",
-		MessageKey:       "msg",
-		CallerKey:        "line",
-		StacktraceKey:    "stacktrace",
+		TimeKey:          keys.TimeKey,
+		LevelKey:         keys.LevelKey,
+		NameKey:          keys.NameKey,
+		MessageKey:       keys.MessageKey,
+		CallerKey:        keys.CallerKey,
+		StacktraceKey:    keys.StacktraceKey,
 		LineEnding:       zapcore.DefaultLineEnding,
-		EncodeLevel:      zapcore.CapitalLevelEncoder,
-		EncodeTime:       zapcore.ISO8601TimeEncoder,
+		EncodeLevel:      newLevelEncoder(option.LevelEncoder, console),
+		EncodeTime:       newTimeEncoder(option.TimeEncoderType, option.TimeLayout),
 		EncodeDuration:   zapcore.SecondsDurationEncoder,
 		EncodeName:       zapcore.FullNameEncoder,
 		ConsoleSeparator: option.ConsoleSeparator,
@@ -64,7 +60,53 @@ func newConsoleEncoder(option *Option) zapcore.Encoder {
 		encoderCfg.EncodeCaller = zapcore.ShortCallerEncoder
 	} else if option.PathEncoderType == FullPathEncoderType {
 		encoderCfg.EncodeCaller = zapcore.FullCallerEncoder
+	} else {
+		encoderCfg.CallerKey = "" // 未设置编码器时 zap 无法编码文件路径
+	}
+
+	switch encoding {
+	case JSONEncoding:
+		return zapcore.NewJSONEncoder(encoderCfg)
+	case LogfmtEncoding:
+		return newLogfmtEncoder(encoderCfg)
+	default:
+		return zapcore.NewConsoleEncoder(encoderCfg)
+	}
+}
+
+func newLevelEncoder(encoderType LevelEncoderType, console bool) zapcore.LevelEncoder {
+	switch {
+	case encoderType == LowercaseLevelEncoderType:
+		return zapcore.LowercaseLevelEncoder
+	case encoderType == CapitalColorLevelEncoderType && console:
+		return zapcore.CapitalColorLevelEncoder
+	case encoderType == LowercaseColorLevelEncoderType && console:
+		return zapcore.LowercaseColorLevelEncoder
+	case encoderType == LowercaseColorLevelEncoderType:
+		return zapcore.LowercaseLevelEncoder
+	default:
+		return zapcore.CapitalLevelEncoder
 	}
+}
 
-	return zapcore.NewConsoleEncoder(encoderCfg)
+func newTimeEncoder(encoderType TimeEncoderType, layout string) zapcore.TimeEncoder {
+	switch encoderType {
+	case RFC3339TimeEncoderType:
+		return zapcore.RFC3339TimeEncoder
+	case RFC3339NanoTimeEncoderType:
+		return zapcore.RFC3339NanoTimeEncoder
+	case EpochTimeEncoderType:
+		return zapcore.EpochTimeEncoder
+	case EpochMillisTimeEncoderType:
+		return zapcore.EpochMillisTimeEncoder
+	case EpochNanosTimeEncoderType:
+		return zapcore.EpochNanosTimeEncoder
+	case LayoutTimeEncoderType:
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return zapcore.TimeEncoderOfLayout(layout)
+	default:
+		return zapcore.ISO8601TimeEncoder
+	}
 }
@@ -0,0 +1,93 @@
+package zap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/wolfbolin/bolbox/pkg/log"
+)
+
+func encode(t *testing.T, option *Option, encoding Encoding, console bool) string {
+	encoder := newEncoder(option, encoding, console)
+	encoder = encoder.Clone()
+	zap.String("request", "r 1").AddTo(encoder)
+
+	entry := zapcore.Entry{
+		Level:   zapcore.InfoLevel,
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 6000000, time.UTC),
+		Message: "hello world",
+	}
+	buf, err := encoder.EncodeEntry(entry, []zapcore.Field{
+		zap.Int("user", 1),
+		zap.Strings("tags", []string{"a", "b"}),
+		zap.String("empty", ""),
+	})
+	assert.Nil(t, err)
+	defer buf.Free()
+	return buf.String()
+}
+
+func TestEncoders(t *testing.T) {
+	option := NewDefaultOption("", log.InfoLevel)
+	option.PathEncoderType = NoPathEncoderType
+
+	assert.Equal(t, "2024-01-02T03:04:05.006Z | INFO | hello world | "+
+		`{"request": "r 1", "user": 1, "tags": ["a", "b"], "empty": ""}`+"\n",
+		encode(t, option, ConsoleEncoding, true))
+
+	option.Keys = EncoderKeys{MessageKey: "message", TimeKey: "ts", LevelKey: OmitKey}
+	option.TimeEncoderType = EpochNanosTimeEncoderType
+	assert.Equal(t, `{"ts":1704164645006000000,"message":"hello world","request":"r 1","user":1,"tags":["a","b"],"empty":""}`+"\n",
+		encode(t, option, JSONEncoding, false))
+
+	option.Keys = EncoderKeys{}
+	option.TimeEncoderType = RFC3339NanoTimeEncoderType
+	option.LevelEncoder = LowercaseColorLevelEncoderType
+	assert.Equal(t, `level=info time=2024-01-02T03:04:05.006Z msg="hello world" request="r 1" user=1 tags="[\"a\",\"b\"]" empty=""`+"\n",
+		encode(t, option, LogfmtEncoding, false))
+
+	option.TimeEncoderType = LayoutTimeEncoderType
+	option.TimeLayout = "2006/01/02 15:04:05"
+	option.LevelEncoder = CapitalColorLevelEncoderType
+	assert.Equal(t, "2024/01/02 03:04:05 | \x1b[34mINFO\x1b[0m | hello world | "+
+		`{"request": "r 1", "user": 1, "tags": ["a", "b"], "empty": ""}`+"\n",
+		encode(t, option, ConsoleEncoding, true))
+}
+
+func TestOptionEncoding(t *testing.T) {
+	option := &Option{}
+	assert.Equal(t, ConsoleEncoding, option.encoding(option.FileEncoding))
+	option.Encoding = JSONEncoding
+	option.ConsoleEncoding = LogfmtEncoding
+	assert.Equal(t, JSONEncoding, option.encoding(option.FileEncoding))
+	assert.Equal(t, LogfmtEncoding, option.encoding(option.ConsoleEncoding))
+}
+
+func TestEncodersCaller(t *testing.T) {
+	entry := zapcore.Entry{
+		Level:   zapcore.InfoLevel,
+		Message: "hello",
+		Caller:  zapcore.NewEntryCaller(0, "/src/app/main.go", 12, true),
+	}
+	option := NewDefaultOption("", log.InfoLevel)
+	option.Keys = EncoderKeys{TimeKey: OmitKey}
+	option.PathEncoderType = NoPathEncoderType
+
+	// 不打印文件路径时带有调用位置的日志同样可以编码
+	for _, encoding := range []Encoding{ConsoleEncoding, JSONEncoding, LogfmtEncoding} {
+		buf, err := newEncoder(option, encoding, false).EncodeEntry(entry, nil)
+		assert.Nil(t, err)
+		assert.NotContains(t, buf.String(), "main.go")
+		buf.Free()
+	}
+
+	option.PathEncoderType = ShortPathEncoderType
+	buf, err := newEncoder(option, JSONEncoding, false).EncodeEntry(entry, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"level":"INFO","line":"app/main.go:12","msg":"hello"}`+"\n", buf.String())
+	buf.Free()
+}
@@ -0,0 +1,96 @@
+package zap
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+var logfmtPool = buffer.NewPool()
+
+// logfmtEncoder 以 logfmt 格式（key=value）输出日志的编码器。
+// 日志先由 JSON 编码器编码以保持字段顺序，再逐个字段转换为 logfmt，嵌套的对象与数组保留为紧凑的 JSON。
+type logfmtEncoder struct {
+	zapcore.Encoder
+	lineEnding string
+}
+
+// newLogfmtEncoder 根据编码器配置创建 logfmt 编码器
+func newLogfmtEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	lineEnding := cfg.LineEnding
+	if lineEnding == "" {
+		lineEnding = zapcore.DefaultLineEnding
+	}
+	cfg.LineEnding = "\n"
+	return &logfmtEncoder{
+		Encoder:    zapcore.NewJSONEncoder(cfg),
+		lineEnding: lineEnding,
+	}
+}
+
+func (e *logfmtEncoder) Clone() zapcore.Encoder {
+	return &logfmtEncoder{
+		Encoder:    e.Encoder.Clone(),
+		lineEnding: e.lineEnding,
+	}
+}
+
+func (e *logfmtEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	jsonBuf, err := e.Encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return nil, err
+	}
+	defer jsonBuf.Free()
+
+	buf := logfmtPool.Get()
+	decoder := json.NewDecoder(bytes.NewReader(jsonBuf.Bytes()))
+	decoder.UseNumber()
+	if _, err = decoder.Token(); err != nil { // {
+		buf.Free()
+		return nil, err
+	}
+	for decoder.More() {
+		keyToken, err := decoder.Token()
+		if err != nil {
+			buf.Free()
+			return nil, err
+		}
+		var raw json.RawMessage
+		if err = decoder.Decode(&raw); err != nil && err != io.EOF {
+			buf.Free()
+			return nil, err
+		}
+		if buf.Len() != 0 {
+			buf.AppendByte(' ')
+		}
+		appendLogfmt(buf, keyToken.(string))
+		buf.AppendByte('=')
+		if len(raw) != 0 && raw[0] == '"' {
+			var value string
+			_ = json.Unmarshal(raw, &value)
+			appendLogfmt(buf, value)
+		} else {
+			appendLogfmt(buf, string(raw))
+		}
+	}
+	buf.AppendString(e.lineEnding)
+	return buf, nil
+}
+
+// appendLogfmt 写入 logfmt 的键或值，包含空白、引号、等号或控制字符的值以及空值使用双引号包裹
+func appendLogfmt(buf *buffer.Buffer, value string) {
+	needQuote := value == "" || strings.IndexFunc(value, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || r == unicode.ReplacementChar || unicode.IsControl(r)
+	}) >= 0
+	if needQuote {
+		buf.AppendString(strconv.Quote(value))
+		return
+	}
+	buf.AppendString(value)
+}
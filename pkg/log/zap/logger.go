@@ -2,43 +2,52 @@ package zap
 
 import (
 	"fmt"
+	"io"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	"github.com/wolfbolin/bolbox/pkg/errors"
 	"github.com/wolfbolin/bolbox/pkg/log"
 )
 
-var _ log.Logger = (*Logger)(nil)
+var (
+	_ log.Logger      = (*Logger)(nil)
+	_ log.FieldLogger = (*Logger)(nil)
+)
 
 // Logger 定义了一个 zap 日志记录器
 type Logger struct {
-	logger *zap.Logger
+	logger  *zap.Logger
+	closers []io.Closer // 由 NewZapLogger 创建的输出资源，子日志记录器与父日志记录器共享
+	name    string      // 日志记录器跟随其级别的具名日志记录器名称，为空时跟随全局级别
 }
 
-// NewLogger 创建并返回一个 Logger 实例
+// NewLogger 创建并返回一个 Logger 实例。
+// PANIC 与 FATAL 级别的日志仅被写入，抛出 panic 与退出进程由 log 包的全局函数完成，与日志实现无关
 func NewLogger(logger *zap.Logger) *Logger {
 	return &Logger{
-		logger: logger,
+		logger: logger.WithOptions(zap.WithPanicHook(writeOnly{}), zap.WithFatalHook(writeOnly{})),
 	}
 }
 
-// Enabled 检查给定的日志级别是否启用
+// writeOnly 写入 PANIC 与 FATAL 级别的日志后不执行任何操作。
+// zap 会将 zapcore.WriteThenNoop 替换为默认的 panic 与退出，因此使用自定义的实现
+type writeOnly struct{}
+
+func (writeOnly) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {}
+
+// Enabled 检查给定的日志级别是否启用，同时考虑 zap 日志自身的级别与 log.SetLevel、log.SetLevelFor 设置的级别
 func (l *Logger) Enabled(level log.Level) bool {
-	return l.logger.Core().Enabled(zapcore.Level(level - 1))
+	return log.LevelEnabled(l.name, level) && l.logger.Core().Enabled(zapcore.Level(level-1))
 }
 
-// Log 用于记录用户日志
+// Log 用于记录用户日志，低于 log.SetLevel 或具名日志记录器级别的日志被忽略
 func (l *Logger) Log(level log.Level, msg string, keyvals ...interface{}) {
-	if len(keyvals)%2 != 0 {
-		l.logger.Warn("keyvals must appear int pairs, len: %d", zap.Int("len", len(keyvals)))
+	if !log.LevelEnabled(l.name, level) {
 		return
 	}
-
-	logData := make([]zap.Field, 0, len(keyvals)/2)
-	for i := 0; i < len(keyvals); i += 2 {
-		logData = append(logData, zap.Any(fmt.Sprint(keyvals[i]), keyvals[i+1]))
-	}
+	logData := fields(keyvals)
 
 	switch level {
 	case log.DebugLevel:
@@ -58,7 +67,55 @@ func (l *Logger) Log(level log.Level, msg string, keyvals ...interface{}) {
 	}
 }
 
+// With 返回一个在每条日志中附加 keyvals 的子日志记录器
+func (l *Logger) With(keyvals ...interface{}) log.Logger {
+	return &Logger{
+		logger:  l.logger.With(fields(keyvals)...),
+		closers: l.closers,
+		name:    log.LoggerName(l.name, keyvals...),
+	}
+}
+
+// fields 将成对出现的 keyvals 转换为 zap 字段，奇数长度时最后一个值的键为 log.BadKey。
+// error 类型的值除错误信息外，还以 key + "Verbose" 为键输出 %+v 格式的错误详情
+func fields(keyvals []interface{}) []zap.Field {
+	fields := make([]zap.Field, 0, (len(keyvals)+1)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		if i+1 == len(keyvals) {
+			fields = append(fields, zap.Any(log.BadKey, keyvals[i]))
+			break
+		}
+		fields = append(fields, zap.Any(fmt.Sprint(keyvals[i]), keyvals[i+1]))
+	}
+	return fields
+}
+
 // Sync 用于确保日志被写入
 func (l *Logger) Sync() error {
 	return l.logger.Sync()
 }
+
+// Close 将缓冲的日志写入输出并释放文件、syslog 连接与异步写入协程，之后的日志同步写入输出
+func (l *Logger) Close() error {
+	errs := make([]error, 0)
+	for _, closer := range l.closers {
+		if err := closer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AsyncStats 返回全部异步写入器的统计之和
+func (l *Logger) AsyncStats() AsyncStats {
+	var stats AsyncStats
+	for _, closer := range l.closers {
+		if async, ok := closer.(*AsyncWriter); ok {
+			asyncStats := async.Stats()
+			stats.Written += asyncStats.Written
+			stats.Dropped += asyncStats.Dropped
+			stats.Queued += asyncStats.Queued
+		}
+	}
+	return stats
+}
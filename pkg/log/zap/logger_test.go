@@ -0,0 +1,89 @@
+package zap
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/wolfbolin/bolbox/pkg/log"
+)
+
+func TestLoggerWith(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	logger := NewLogger(zap.New(core))
+
+	child := logger.With("request", "r1")
+	child.Log(log.InfoLevel, "hello", "user", 1)
+	logger.Log(log.InfoLevel, "plain")
+
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, map[string]interface{}{"request": "r1", "user": int64(1)}, entries[0].ContextMap())
+	assert.Empty(t, entries[1].ContextMap())
+}
+
+func TestLoggerTerminalLevels(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	logger := NewLogger(zap.New(core))
+
+	// 抛出 panic 与退出进程由 log 包的全局函数完成
+	assert.NotPanics(t, func() {
+		logger.Log(log.PanicLevel, "panic")
+		logger.Log(log.FatalLevel, "fatal")
+	})
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, zapcore.PanicLevel, entries[0].Level)
+	assert.Equal(t, zapcore.FatalLevel, entries[1].Level)
+}
+
+func TestLoggerFields(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	logger := NewLogger(zap.New(core))
+
+	// 奇数长度的 keyvals 不再丢弃日志
+	logger.With("request", "r1").Log(log.InfoLevel, "hello", "user", 1, "dangling")
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, map[string]interface{}{"request": "r1", "user": int64(1), log.BadKey: "dangling"}, entries[0].ContextMap())
+}
+
+func TestLoggerGlobalLevel(t *testing.T) {
+	defer log.SetLevel(log.DebugLevel)
+	defer log.ResetLevel("db")
+	core, logs := observer.New(zap.DebugLevel)
+	logger := NewLogger(zap.New(core))
+	log.SetLevel(log.WarnLevel)
+	log.SetLevelFor("db", log.DebugLevel, 0)
+
+	// 直接使用日志记录器时同样遵循 log.SetLevel 与具名日志记录器的级别
+	logger.Log(log.InfoLevel, "filtered")
+	logger.With(log.NameKey, "db").Log(log.DebugLevel, "named")
+	logger.Log(log.WarnLevel, "warn")
+	assert.False(t, logger.Enabled(log.InfoLevel))
+	assert.True(t, logger.With(log.NameKey, "db").Enabled(log.DebugLevel))
+
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "named", entries[0].Message)
+	assert.Equal(t, "warn", entries[1].Message)
+}
+
+func TestFollowLogLevel(t *testing.T) {
+	defer log.SetLevel(log.DebugLevel)
+	option := NewDefaultOption("", log.ErrorLevel)
+	option.ConsoleLogger = false
+	option.Sinks = []Sink{{Type: WriterSink, Writer: &bytes.Buffer{}}}
+	assert.False(t, NewZapLogger(option).Enabled(log.InfoLevel))
+
+	// 跟随 log 包的级别时 LogLevel 不生效
+	option.FollowLogLevel = true
+	logger := NewZapLogger(option)
+	assert.True(t, logger.Enabled(log.InfoLevel))
+	log.SetLevel(log.WarnLevel)
+	assert.False(t, logger.Enabled(log.InfoLevel))
+}
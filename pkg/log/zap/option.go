@@ -1,6 +1,8 @@
 package zap
 
 import (
+	"time"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
@@ -19,16 +21,95 @@ const (
 	FullPathEncoderType
 )
 
+// Encoding 表示日志的编码格式
+type Encoding string
+
+const (
+	// ConsoleEncoding 以分隔符分隔字段的控制台格式
+	ConsoleEncoding Encoding = "console"
+	// JSONEncoding 每行一个 JSON 对象
+	JSONEncoding Encoding = "json"
+	// LogfmtEncoding 以空格分隔的 key=value 格式
+	LogfmtEncoding Encoding = "logfmt"
+)
+
+// TimeEncoderType 表示时间编码器类型
+type TimeEncoderType int
+
+const (
+	// ISO8601TimeEncoderType 以毫秒精度的 ISO8601 格式打印时间
+	ISO8601TimeEncoderType TimeEncoderType = iota
+	// RFC3339TimeEncoderType 以 RFC3339 格式打印时间
+	RFC3339TimeEncoderType
+	// RFC3339NanoTimeEncoderType 以纳秒精度的 RFC3339 格式打印时间
+	RFC3339NanoTimeEncoderType
+	// EpochTimeEncoderType 以浮点数秒打印 Unix 时间戳
+	EpochTimeEncoderType
+	// EpochMillisTimeEncoderType 以浮点数毫秒打印 Unix 时间戳
+	EpochMillisTimeEncoderType
+	// EpochNanosTimeEncoderType 以整数纳秒打印 Unix 时间戳
+	EpochNanosTimeEncoderType
+	// LayoutTimeEncoderType 以 TimeLayout 指定的格式打印时间
+	LayoutTimeEncoderType
+)
+
+// LevelEncoderType 表示日志级别编码器类型
+type LevelEncoderType int
+
+const (
+	// CapitalLevelEncoderType 以大写字母打印日志级别，如 INFO
+	CapitalLevelEncoderType LevelEncoderType = iota
+	// LowercaseLevelEncoderType 以小写字母打印日志级别，如 info
+	LowercaseLevelEncoderType
+	// CapitalColorLevelEncoderType 以带颜色的大写字母打印日志级别，仅用于控制台，文件中不带颜色
+	CapitalColorLevelEncoderType
+	// LowercaseColorLevelEncoderType 以带颜色的小写字母打印日志级别，仅用于控制台，文件中不带颜色
+	LowercaseColorLevelEncoderType
+)
+
+// OmitKey 作为 EncoderKeys 中的键名时表示不打印对应的内容
+const OmitKey = "-"
+
+// EncoderKeys 日志内容在编码结果中的键名，为空时使用默认键名，为 OmitKey 时不打印
+type EncoderKeys struct {
+	TimeKey       string // 默认为 time
+	LevelKey      string // 默认为 level
+	NameKey       string // 默认为 logger
+	CallerKey     string // 默认为 line
+	MessageKey    string // 默认为 msg
+	StacktraceKey string // 默认为 stacktrace
+}
+
+// Sampling zap 日志的采样选项，每个 Tick 内相同级别与消息的日志仅输出前 First 条，之后每 Thereafter 条输出一条
+type Sampling struct {
+	Tick       time.Duration
+	First      int
+	Thereafter int
+}
+
 // Option 包含 zap 日志库的可定制选项
 type Option struct {
-	FilePath      string // 如果为空则表示不向文件打印日志
-	LogLevel      zap.AtomicLevel
-	ConsoleLogger bool // 是否打印控制台日志
-	CallSkip      int  // 跳过调用函数的数量
-	Stack         bool // 是否打印堆栈
+	FilePath       string // 如果为空则表示不向文件打印日志
+	LogLevel       zap.AtomicLevel
+	FollowLogLevel bool // 是否仅由 log.SetLevel 与具名日志记录器的级别过滤日志，为 true 时 LogLevel 不生效
+	ConsoleLogger  bool // 是否打印控制台日志
+	CallSkip       int  // 跳过调用函数的数量
+	Stack          bool // 是否打印堆栈
 
 	PathEncoderType  PathEncoderType // 文件路径编码类型：不打印、段路径编码、全路径编码
-	ConsoleSeparator string          // 日志字段的分隔符
+	ConsoleSeparator string          // 日志字段的分隔符，仅用于控制台格式
+
+	Encoding        Encoding         // 日志的编码格式，为空时使用控制台格式
+	ConsoleEncoding Encoding         // 控制台日志的编码格式，为空时使用 Encoding
+	FileEncoding    Encoding         // 文件日志的编码格式，为空时使用 Encoding
+	Keys            EncoderKeys      // 日志内容的键名
+	TimeEncoderType TimeEncoderType  // 时间编码类型
+	TimeLayout      string           // 时间编码类型为 LayoutTimeEncoderType 时使用的时间格式
+	LevelEncoder    LevelEncoderType // 日志级别编码类型
+
+	Sinks    []Sink    // 日志的输出列表，为空时根据 FilePath 与 ConsoleLogger 输出到文件与标准输出
+	Sampling *Sampling // 日志的采样选项，为空时不采样
+	Async    *Async    // 异步写入选项，为空时同步写入，syslog 输出始终同步写入
 
 	MaxSize    int // 每个日志文件保存的最大尺寸 单位：M
 	MaxBackups int // 日志文件最多保存多少个备份
@@ -45,6 +126,7 @@ func NewDefaultOption(path string, level log.Level) *Option {
 		Stack:            false,
 		PathEncoderType:  ShortPathEncoderType,
 		ConsoleSeparator: " | ",
+		Encoding:         ConsoleEncoding,
 		MaxSize:          20,
 		MaxBackups:       50,
 		MaxAge:           30,
@@ -55,3 +137,36 @@ func NewDefaultOption(path string, level log.Level) *Option {
 func (o *Option) SetLogLevel(level log.Level) {
 	o.LogLevel.SetLevel(zapcore.Level(level - 1))
 }
+
+// encoding 返回指定输出使用的编码格式，为空时使用 Encoding
+func (o *Option) encoding(encoding Encoding) Encoding {
+	if encoding != "" {
+		return encoding
+	}
+	if o.Encoding != "" {
+		return o.Encoding
+	}
+	return ConsoleEncoding
+}
+
+// withDefaults 返回以默认键名补全后的键名，OmitKey 被转换为空键名以不打印对应的内容
+func (k EncoderKeys) withDefaults() EncoderKeys {
+	key := func(value, def string) string {
+		switch value {
+		case "":
+			return def
+		case OmitKey:
+			return ""
+		default:
+			return value
+		}
+	}
+	return EncoderKeys{
+		TimeKey:       key(k.TimeKey, "time"),
+		LevelKey:      key(k.LevelKey, "level"),
+		NameKey:       key(k.NameKey, "logger"),
+		CallerKey:     key(k.CallerKey, "line"),
+		MessageKey:    key(k.MessageKey, "msg"),
+		StacktraceKey: key(k.StacktraceKey, "stacktrace"),
+	}
+}
@@ -0,0 +1,163 @@
+package zap
+
+import (
+	"io"
+	"os"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+	"github.com/wolfbolin/bolbox/pkg/log"
+)
+
+// SinkType 表示日志输出的类型
+type SinkType string
+
+const (
+	// StdoutSink 输出到标准输出
+	StdoutSink SinkType = "stdout"
+	// StderrSink 输出到标准错误
+	StderrSink SinkType = "stderr"
+	// FileSink 输出到按大小滚动的文件
+	FileSink SinkType = "file"
+	// SyslogSink 通过本地套接字或网络输出到 syslog
+	SyslogSink SinkType = "syslog"
+	// WriterSink 输出到任意 io.Writer
+	WriterSink SinkType = "writer"
+)
+
+// Rotation 文件日志的滚动策略
+type Rotation struct {
+	MaxSize    int  // 每个日志文件保存的最大尺寸 单位：M
+	MaxBackups int  // 日志文件最多保存多少个备份
+	MaxAge     int  // 文件最多保存多少天
+	Compress   bool // 是否压缩滚动后的日志文件
+}
+
+// Sink 日志的一个输出，每个输出拥有独立的日志级别、编码格式与滚动策略
+type Sink struct {
+	Type     SinkType
+	Level    log.Level // 输出的最低日志级别，同时受 Option.LogLevel 限制
+	Encoding Encoding  // 输出的编码格式，为空时使用 Option 中对应的编码格式
+
+	Path     string    // FileSink 的文件路径
+	Rotation *Rotation // FileSink 的滚动策略，为空时使用 Option 中的滚动策略并压缩滚动后的文件
+
+	Network string // SyslogSink 的网络类型，为空时使用本地套接字 unixgram
+	Address string // SyslogSink 的地址，为空时使用 /dev/log
+	Tag     string // SyslogSink 的标签，为空时使用进程名
+
+	Writer io.Writer // WriterSink 的输出
+}
+
+// sinks 返回日志选项中的输出列表，未设置 Sinks 时由 FilePath 与 ConsoleLogger 生成
+func (o *Option) sinks() []Sink {
+	if len(o.Sinks) != 0 {
+		return o.Sinks
+	}
+	sinks := make([]Sink, 0, 2)
+	if o.FilePath != "" {
+		sinks = append(sinks, Sink{Type: FileSink, Path: o.FilePath})
+	}
+	if o.ConsoleLogger {
+		sinks = append(sinks, Sink{Type: StdoutSink})
+	}
+	return sinks
+}
+
+// newSinkCore 根据输出创建日志核心，创建失败时返回错误。输出关闭时需要释放的资源按释放顺序追加到 closers 中
+func newSinkCore(option *Option, sink Sink, closers *[]io.Closer) (zapcore.Core, error) {
+	minLevel := zapcore.Level(sink.Level - 1)
+	enabler := zap.LevelEnablerFunc(func(level zapcore.Level) bool {
+		return level >= minLevel && (option.FollowLogLevel || option.LogLevel.Enabled(level))
+	})
+
+	console := sink.Type == StdoutSink || sink.Type == StderrSink
+	encoding := sink.Encoding
+	if encoding == "" && console {
+		encoding = option.encoding(option.ConsoleEncoding)
+	} else if encoding == "" {
+		encoding = option.encoding(option.FileEncoding)
+	}
+	encoder := newEncoder(option, encoding, console)
+
+	var writer zapcore.WriteSyncer
+	var file io.Closer // 文件输出，在异步写入器之后关闭
+	switch sink.Type {
+	case StdoutSink:
+		writer = zapcore.Lock(os.Stdout)
+	case StderrSink:
+		writer = zapcore.Lock(os.Stderr)
+	case FileSink:
+		if sink.Path == "" {
+			return nil, errors.New("File sink requires a path")
+		}
+		rotation := newRotationSyncer(option, sink)
+		file, writer = rotation, zapcore.AddSync(rotation)
+	case WriterSink:
+		if sink.Writer == nil {
+			return nil, errors.New("Writer sink requires a writer")
+		}
+		writer = zapcore.Lock(zapcore.AddSync(sink.Writer))
+	case SyslogSink:
+		core, err := newSyslogCore(sink, encoder, enabler)
+		if err != nil {
+			return nil, err
+		}
+		*closers = append(*closers, core.writer)
+		return core, nil
+	default:
+		return nil, errors.Errorf("Unknown sink type %q", sink.Type)
+	}
+
+	if option.Async != nil {
+		async := NewAsyncWriter(writer, *option.Async)
+		*closers = append(*closers, async)
+		writer = async
+		if file != nil {
+			file = &asyncFileCloser{async: async, file: file}
+		}
+	}
+	if file != nil {
+		*closers = append(*closers, file)
+	}
+	return zapcore.NewCore(encoder, writer, enabler), nil
+}
+
+// asyncFileCloser 在异步写入器的后台写入协程退出后关闭文件。
+// 关闭异步写入器超时后后台协程仍在写入剩余的日志，此时文件保持打开，避免写入已关闭的文件
+type asyncFileCloser struct {
+	async *AsyncWriter
+	file  io.Closer
+}
+
+func (c *asyncFileCloser) Close() error {
+	select {
+	case <-c.async.done:
+		return c.file.Close()
+	default:
+		return errors.New("Log file is left open since the async writer is still writing queued logs")
+	}
+}
+
+func newRotationSyncer(option *Option, sink Sink) *lumberjack.Logger {
+	rotation := sink.Rotation
+	if rotation == nil {
+		rotation = &Rotation{
+			MaxSize:    option.MaxSize,
+			MaxBackups: option.MaxBackups,
+			MaxAge:     option.MaxAge,
+			Compress:   true,
+		}
+	}
+	return &lumberjack.Logger{
+		Filename:   sink.Path,
+		MaxSize:    rotation.MaxSize,
+		MaxBackups: rotation.MaxBackups,
+		MaxAge:     rotation.MaxAge,
+		Compress:   rotation.Compress,
+		LocalTime:  true,
+	}
+}
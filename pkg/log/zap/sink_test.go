@@ -0,0 +1,83 @@
+package zap
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wolfbolin/bolbox/pkg/log"
+)
+
+func TestSinks(t *testing.T) {
+	dir := t.TempDir()
+	errorPath := filepath.Join(dir, "error.log")
+	var all, warn bytes.Buffer
+
+	option := NewDefaultOption("", log.DebugLevel)
+	option.PathEncoderType = NoPathEncoderType
+	option.Sinks = []Sink{
+		{Type: WriterSink, Writer: &all, Encoding: LogfmtEncoding},
+		{Type: WriterSink, Writer: &warn, Level: log.WarnLevel, Encoding: JSONEncoding},
+		{Type: FileSink, Path: errorPath, Level: log.ErrorLevel, Rotation: &Rotation{MaxSize: 1}},
+		{Type: FileSink}, // 缺少文件路径的输出被忽略
+	}
+	logger := NewZapLogger(option)
+	logger.Log(log.DebugLevel, "debug message")
+	logger.Log(log.WarnLevel, "warn message", "key", "value")
+	logger.Log(log.ErrorLevel, "error message")
+	assert.Nil(t, logger.Sync())
+
+	assert.Equal(t, 3, strings.Count(all.String(), "\n"))
+	assert.Contains(t, all.String(), `msg="debug message"`)
+	assert.Equal(t, 2, strings.Count(warn.String(), "\n"))
+	assert.Contains(t, warn.String(), `"msg":"warn message","key":"value"`)
+	content, err := os.ReadFile(errorPath)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, strings.Count(string(content), "\n"))
+	assert.Contains(t, string(content), "error message")
+
+	// Option 的日志级别同时限制全部输出
+	option.SetLogLevel(log.ErrorLevel)
+	logger.Log(log.WarnLevel, "filtered message")
+	assert.NotContains(t, all.String(), "filtered message")
+}
+
+func TestSyslogSink(t *testing.T) {
+	address := filepath.Join(t.TempDir(), "syslog.sock")
+	conn, err := net.ListenPacket("unixgram", address)
+	if err != nil {
+		t.Skipf("Unix datagram socket is not supported. %v", err)
+	}
+	defer conn.Close()
+
+	option := NewDefaultOption("", log.InfoLevel)
+	option.PathEncoderType = NoPathEncoderType
+	option.Keys = EncoderKeys{TimeKey: OmitKey, LevelKey: OmitKey}
+	option.Sinks = []Sink{{Type: SyslogSink, Address: address, Tag: "bolbox", Encoding: LogfmtEncoding}}
+	logger := NewZapLogger(option)
+	logger.Log(log.WarnLevel, "syslog message", "key", "value")
+
+	buf := make([]byte, 1024)
+	n, _, err := conn.ReadFrom(buf)
+	assert.Nil(t, err)
+	assert.Regexp(t, `^<12>bolbox\[\d+\]: msg="syslog message" key=value\n$`, string(buf[:n]))
+}
+
+func TestSampling(t *testing.T) {
+	var buf bytes.Buffer
+	option := NewDefaultOption("", log.InfoLevel)
+	option.Sinks = []Sink{{Type: WriterSink, Writer: &buf}}
+	option.Sampling = &Sampling{Tick: time.Minute, First: 2, Thereafter: 5}
+	logger := NewZapLogger(option)
+	for i := 0; i < 12; i++ {
+		logger.Log(log.WarnLevel, "noisy")
+	}
+	// 前 2 条全部输出，之后每 5 条输出一条
+	assert.Equal(t, 4, strings.Count(buf.String(), "noisy"))
+}
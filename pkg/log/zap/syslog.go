@@ -0,0 +1,143 @@
+package zap
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// syslog 的设施与严重级别，设施固定为 user
+const (
+	syslogFacilityUser = 1 << 3
+
+	syslogCrit    = 2
+	syslogErr     = 3
+	syslogWarning = 4
+	syslogInfo    = 6
+	syslogDebug   = 7
+)
+
+// syslogWriter 向 syslog 写入消息，写入失败时重新连接一次
+type syslogWriter struct {
+	network, address, tag string
+
+	lock sync.Mutex
+	conn net.Conn
+}
+
+func (w *syslogWriter) connect() error {
+	if w.conn != nil {
+		return nil
+	}
+	conn, err := net.Dial(w.network, w.address)
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+// write 写入一条指定优先级的消息
+func (w *syslogWriter) write(priority int, message []byte) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	packet := append([]byte(fmt.Sprintf("<%d>%s[%d]: ", priority, w.tag, os.Getpid())), message...)
+	for retry := 0; ; retry++ {
+		err := w.connect()
+		if err == nil {
+			if _, err = w.conn.Write(packet); err == nil {
+				return nil
+			}
+			_ = w.conn.Close()
+			w.conn = nil
+		}
+		if retry > 0 {
+			return err
+		}
+	}
+}
+
+// Close 关闭与 syslog 的连接，之后写入时重新连接
+func (w *syslogWriter) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// syslogCore 将日志写入 syslog 的日志核心，syslog 的严重级别由日志级别决定
+type syslogCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	writer  *syslogWriter
+}
+
+// newSyslogCore 创建 syslog 日志核心，未指定地址时连接本地的 /dev/log
+func newSyslogCore(sink Sink, encoder zapcore.Encoder, enabler zapcore.LevelEnabler) (*syslogCore, error) {
+	writer := &syslogWriter{network: sink.Network, address: sink.Address, tag: sink.Tag}
+	if writer.network == "" {
+		writer.network = "unixgram"
+	}
+	if writer.address == "" {
+		writer.address = "/dev/log"
+	}
+	if writer.tag == "" {
+		writer.tag = filepath.Base(os.Args[0])
+	}
+	if err := writer.connect(); err != nil {
+		return nil, err
+	}
+	return &syslogCore{LevelEnabler: enabler, encoder: encoder, writer: writer}, nil
+}
+
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &syslogCore{LevelEnabler: c.LevelEnabler, encoder: c.encoder.Clone(), writer: c.writer}
+	for _, field := range fields {
+		field.AddTo(clone.encoder)
+	}
+	return clone
+}
+
+func (c *syslogCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *syslogCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buffer, err := c.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	defer buffer.Free()
+	return c.writer.write(syslogFacilityUser|syslogSeverity(entry.Level), buffer.Bytes())
+}
+
+func (c *syslogCore) Sync() error {
+	return nil
+}
+
+// syslogSeverity 将日志级别转换为 syslog 的严重级别
+func syslogSeverity(level zapcore.Level) int {
+	switch {
+	case level >= zapcore.DPanicLevel:
+		return syslogCrit
+	case level >= zapcore.ErrorLevel:
+		return syslogErr
+	case level >= zapcore.WarnLevel:
+		return syslogWarning
+	case level >= zapcore.InfoLevel:
+		return syslogInfo
+	default:
+		return syslogDebug
+	}
+}
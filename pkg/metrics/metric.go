@@ -0,0 +1,226 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// labelSep 拼接标签取值时使用的分隔符
+const labelSep = "\xff"
+
+// DefaultBuckets 直方图默认的桶上界，适用于以秒为单位的耗时统计
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// vector 按标签取值分组保存指标取值
+type vector struct {
+	d      *Desc
+	lock   sync.Mutex
+	values map[string]*float64
+	labels map[string][]string
+}
+
+func newVector(desc *Desc) *vector {
+	return &vector{
+		d:      desc,
+		values: make(map[string]*float64),
+		labels: make(map[string][]string),
+	}
+}
+
+func (v *vector) desc() *Desc {
+	return v.d
+}
+
+// update 在锁保护下修改指定标签取值对应的指标值
+func (v *vector) update(labelValues []string, fn func(value *float64)) {
+	key := strings.Join(labelValues, labelSep)
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	value, ok := v.values[key]
+	if !ok {
+		value = new(float64)
+		v.values[key] = value
+		v.labels[key] = append([]string{}, labelValues...)
+	}
+	fn(value)
+}
+
+// get 返回指定标签取值对应的指标值
+func (v *vector) get(labelValues []string) float64 {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if value, ok := v.values[strings.Join(labelValues, labelSep)]; ok {
+		return *value
+	}
+	return 0
+}
+
+func (v *vector) collect(emit func(sample Sample)) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	keys := make([]string, 0, len(v.values))
+	for key := range v.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		emit(Sample{LabelNames: v.d.LabelNames, LabelValues: v.labels[key], Value: *v.values[key]})
+	}
+}
+
+// Counter 单调递增的计数器
+type Counter struct {
+	*vector
+}
+
+// Inc 计数器加一
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add 计数器增加 delta，delta 为负数时忽略
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+	c.update(labelValues, func(value *float64) {
+		*value += delta
+	})
+}
+
+// Value 返回计数器当前的取值
+func (c *Counter) Value(labelValues ...string) float64 {
+	return c.get(labelValues)
+}
+
+// Gauge 可增可减的仪表盘
+type Gauge struct {
+	*vector
+}
+
+// Set 设置仪表盘的取值
+func (g *Gauge) Set(val float64, labelValues ...string) {
+	g.update(labelValues, func(value *float64) {
+		*value = val
+	})
+}
+
+// Add 仪表盘增加 delta，delta 可以为负数
+func (g *Gauge) Add(delta float64, labelValues ...string) {
+	g.update(labelValues, func(value *float64) {
+		*value += delta
+	})
+}
+
+// Value 返回仪表盘当前的取值
+func (g *Gauge) Value(labelValues ...string) float64 {
+	return g.get(labelValues)
+}
+
+// Histogram 按桶统计分布的直方图
+type Histogram struct {
+	d       *Desc
+	buckets []float64
+	lock    sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labels []string
+	counts []uint64 // 每个桶内（非累计）的观测数量，最后一个为 +Inf 桶
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(desc *Desc, buckets []float64) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64{}, buckets...)
+	sort.Float64s(buckets)
+	return &Histogram{
+		d:       desc,
+		buckets: buckets,
+		series:  make(map[string]*histogramSeries),
+	}
+}
+
+func (h *Histogram) desc() *Desc {
+	return h.d
+}
+
+// Observe 记录一次观测值
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	key := strings.Join(labelValues, labelSep)
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	series, ok := h.series[key]
+	if !ok {
+		series = &histogramSeries{
+			labels: append([]string{}, labelValues...),
+			counts: make([]uint64, len(h.buckets)+1),
+		}
+		h.series[key] = series
+	}
+	series.counts[sort.SearchFloat64s(h.buckets, value)] += 1
+	series.sum += value
+	series.count += 1
+}
+
+// Count 返回观测值的数量
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if series, ok := h.series[strings.Join(labelValues, labelSep)]; ok {
+		return series.count
+	}
+	return 0
+}
+
+func (h *Histogram) collect(emit func(sample Sample)) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	bucketNames := append(append([]string{}, h.d.LabelNames...), "le")
+	for _, key := range keys {
+		series := h.series[key]
+		cumulative := uint64(0)
+		for i, count := range series.counts {
+			cumulative += count
+			upper := math.Inf(1)
+			if i < len(h.buckets) {
+				upper = h.buckets[i]
+			}
+			emit(Sample{
+				Suffix:      "_bucket",
+				LabelNames:  bucketNames,
+				LabelValues: append(append([]string{}, series.labels...), formatFloat(upper)),
+				Value:       float64(cumulative),
+			})
+		}
+		emit(Sample{Suffix: "_sum", LabelNames: h.d.LabelNames, LabelValues: series.labels, Value: series.sum})
+		emit(Sample{Suffix: "_count", LabelNames: h.d.LabelNames, LabelValues: series.labels, Value: float64(series.count)})
+	}
+}
+
+// formatFloat 按 Prometheus 文本格式输出浮点数
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
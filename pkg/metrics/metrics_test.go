@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	registry := NewRegistry()
+	requests := registry.Counter("http_requests_total", "Total http requests.", "method", "code")
+	requests.Inc("GET", "200")
+	requests.Add(2, "GET", "200")
+	requests.Inc("POST", "500")
+	requests.Add(-1, "POST", "500")
+	assert.Equal(t, float64(3), requests.Value("GET", "200"))
+
+	// 同名指标只会被创建一次
+	assert.Same(t, requests, registry.Counter("http_requests_total", "Total http requests.", "method", "code"))
+	assert.Panics(t, func() {
+		registry.Gauge("http_requests_total", "")
+	})
+
+	inflight := registry.Gauge("inflight", "In flight \"requests\"\nnow.")
+	inflight.Set(3)
+	inflight.Add(-1)
+
+	latency := registry.Histogram("latency_seconds", "", []float64{0.5, 0.1}, "path")
+	latency.Observe(0.05, `/a"b`)
+	latency.Observe(0.1, `/a"b`)
+	latency.Observe(3, `/a"b`)
+	assert.Equal(t, uint64(3), latency.Count(`/a"b`))
+
+	registry.GaugeFunc("up", "Module is up.", []string{"module"}, func(emit func(value float64, labelValues ...string)) {
+		emit(1, "db")
+	})
+
+	buffer := bytes.Buffer{}
+	assert.Nil(t, registry.WriteText(&buffer))
+	assert.Equal(t, `# HELP http_requests_total Total http requests.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",code="200"} 3
+http_requests_total{method="POST",code="500"} 1
+# HELP inflight In flight "requests"\nnow.
+# TYPE inflight gauge
+inflight 2
+# TYPE latency_seconds histogram
+latency_seconds_bucket{path="/a\"b",le="0.1"} 2
+latency_seconds_bucket{path="/a\"b",le="0.5"} 2
+latency_seconds_bucket{path="/a\"b",le="+Inf"} 3
+latency_seconds_sum{path="/a\"b"} 3.15
+latency_seconds_count{path="/a\"b"} 3
+# HELP up Module is up.
+# TYPE up gauge
+up{module="db"} 1
+`, buffer.String())
+
+	registry.Unregister("up")
+	rec := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, TextContentType, rec.Header().Get("Content-Type"))
+	assert.NotContains(t, rec.Body.String(), "up{")
+}
@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+)
+
+// Kind 指标类型
+type Kind string
+
+const (
+	// KindCounter 单调递增的计数器
+	KindCounter Kind = "counter"
+	// KindGauge 可增可减的仪表盘
+	KindGauge Kind = "gauge"
+	// KindHistogram 按桶统计分布的直方图
+	KindHistogram Kind = "histogram"
+)
+
+// Default 默认的指标注册表
+var Default = NewRegistry()
+
+// Registry 指标注册表，同名指标只会被创建一次
+type Registry struct {
+	lock    sync.RWMutex
+	metrics map[string]metric
+}
+
+// metric 注册表中各类指标的统一定义
+type metric interface {
+	desc() *Desc
+	collect(emit func(sample Sample))
+}
+
+// Desc 指标的描述信息
+type Desc struct {
+	Name       string
+	Help       string
+	Kind       Kind
+	LabelNames []string
+}
+
+// Sample 指标的一个采样值，直方图的采样使用 Suffix 区分 _bucket、_sum 与 _count
+type Sample struct {
+	Suffix      string
+	LabelNames  []string
+	LabelValues []string
+	Value       float64
+}
+
+// NewRegistry 创建一个新的指标注册表
+func NewRegistry() *Registry {
+	return &Registry{
+		metrics: make(map[string]metric),
+	}
+}
+
+// Counter 获取或创建计数器，同名指标的类型或标签不一致时将抛出 panic
+func (r *Registry) Counter(name, help string, labelNames ...string) *Counter {
+	return register(r, &Desc{Name: name, Help: help, Kind: KindCounter, LabelNames: labelNames}, func(desc *Desc) *Counter {
+		return &Counter{vector: newVector(desc)}
+	})
+}
+
+// Gauge 获取或创建仪表盘，同名指标的类型或标签不一致时将抛出 panic
+func (r *Registry) Gauge(name, help string, labelNames ...string) *Gauge {
+	return register(r, &Desc{Name: name, Help: help, Kind: KindGauge, LabelNames: labelNames}, func(desc *Desc) *Gauge {
+		return &Gauge{vector: newVector(desc)}
+	})
+}
+
+// Histogram 获取或创建直方图，buckets 为升序的桶上界，为空时使用 DefaultBuckets
+func (r *Registry) Histogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	return register(r, &Desc{Name: name, Help: help, Kind: KindHistogram, LabelNames: labelNames}, func(desc *Desc) *Histogram {
+		return newHistogram(desc, buckets)
+	})
+}
+
+// CounterFunc 注册在采集时计算取值的计数器，collect 通过 emit 输出每组标签的取值
+func (r *Registry) CounterFunc(name, help string, labelNames []string, collect func(emit func(value float64, labelValues ...string))) {
+	register(r, &Desc{Name: name, Help: help, Kind: KindCounter, LabelNames: labelNames}, func(desc *Desc) *funcMetric {
+		return &funcMetric{d: desc, fn: collect}
+	})
+}
+
+// GaugeFunc 注册在采集时计算取值的仪表盘，collect 通过 emit 输出每组标签的取值
+func (r *Registry) GaugeFunc(name, help string, labelNames []string, collect func(emit func(value float64, labelValues ...string))) {
+	register(r, &Desc{Name: name, Help: help, Kind: KindGauge, LabelNames: labelNames}, func(desc *Desc) *funcMetric {
+		return &funcMetric{d: desc, fn: collect}
+	})
+}
+
+// Unregister 从注册表中移除指定名称的指标
+func (r *Registry) Unregister(name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.metrics, name)
+}
+
+// Gather 按名称顺序采集注册表中的全部指标
+func (r *Registry) Gather(visit func(desc *Desc, samples []Sample)) {
+	r.lock.RLock()
+	metrics := make([]metric, 0, len(r.metrics))
+	for _, m := range r.metrics {
+		metrics = append(metrics, m)
+	}
+	r.lock.RUnlock()
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].desc().Name < metrics[j].desc().Name
+	})
+
+	for _, m := range metrics {
+		samples := make([]Sample, 0)
+		m.collect(func(sample Sample) {
+			samples = append(samples, sample)
+		})
+		visit(m.desc(), samples)
+	}
+}
+
+// register 获取已注册的同名指标，不存在时使用 create 创建并注册
+func register[T metric](r *Registry, desc *Desc, create func(desc *Desc) T) T {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if exist, ok := r.metrics[desc.Name]; ok {
+		typed, ok := exist.(T)
+		if !ok || exist.desc().Kind != desc.Kind || !sameNames(exist.desc().LabelNames, desc.LabelNames) {
+			panic(errors.Errorf("Metric[%s] has been registered as %s%v", desc.Name, exist.desc().Kind, exist.desc().LabelNames))
+		}
+		return typed
+	}
+	m := create(desc)
+	r.metrics[desc.Name] = m
+	return m
+}
+
+func sameNames(a, b []string) bool {
+	return strings.Join(a, labelSep) == strings.Join(b, labelSep)
+}
+
+// funcMetric 在采集时计算取值的指标
+type funcMetric struct {
+	d  *Desc
+	fn func(emit func(value float64, labelValues ...string))
+}
+
+func (f *funcMetric) desc() *Desc {
+	return f.d
+}
+
+func (f *funcMetric) collect(emit func(sample Sample)) {
+	f.fn(func(value float64, labelValues ...string) {
+		emit(Sample{LabelNames: f.d.LabelNames, LabelValues: labelValues, Value: value})
+	})
+}
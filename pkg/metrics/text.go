@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+	"github.com/wolfbolin/bolbox/pkg/log"
+)
+
+// TextContentType Prometheus 文本格式的内容类型
+const TextContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// WriteText 将注册表中的全部指标以 Prometheus 文本格式写出
+func (r *Registry) WriteText(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	r.Gather(func(desc *Desc, samples []Sample) {
+		if desc.Help != "" {
+			writer.WriteString("# HELP " + desc.Name + " " + helpEscaper.Replace(desc.Help) + "\n")
+		}
+		writer.WriteString("# TYPE " + desc.Name + " " + string(desc.Kind) + "\n")
+		for _, sample := range samples {
+			writer.WriteString(desc.Name + sample.Suffix)
+			writeLabels(writer, sample.LabelNames, sample.LabelValues)
+			writer.WriteString(" " + formatFloat(sample.Value) + "\n")
+		}
+	})
+	if err := writer.Flush(); err != nil {
+		return errors.Wrapf(err, "Write metrics text failed")
+	}
+	return nil
+}
+
+// Handler 返回以 Prometheus 文本格式输出注册表中全部指标的 http.Handler
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", TextContentType)
+		if err := r.WriteText(w); err != nil {
+			log.Errorf("Write metrics response failed: %+v", err)
+		}
+	})
+}
+
+func writeLabels(writer *bufio.Writer, names, values []string) {
+	if len(names) == 0 {
+		return
+	}
+	writer.WriteString("{")
+	for i, name := range names {
+		if i != 0 {
+			writer.WriteString(",")
+		}
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		writer.WriteString(name + `="` + labelEscaper.Replace(value) + `"`)
+	}
+	writer.WriteString("}")
+}
@@ -0,0 +1,226 @@
+package scheduler
+
+import (
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+)
+
+// Schedule 任务的调度计划
+type Schedule interface {
+	// Next 返回晚于 after 的下一次执行时间，返回零值表示不再执行
+	Next(after time.Time) time.Time
+}
+
+// Every 返回按固定间隔执行的调度计划，间隔不大于 0 时不再执行
+func Every(interval time.Duration) Schedule {
+	return every(interval)
+}
+
+type every time.Duration
+
+func (e every) Next(after time.Time) time.Time {
+	if e <= 0 {
+		return time.Time{}
+	}
+	return after.Add(time.Duration(e))
+}
+
+// cronSchedule 由 Cron 表达式描述的调度计划，每个字段使用位集合表示允许的取值
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+// cronField Cron 表达式字段的取值范围与别名
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// descriptors Cron 表达式的预定义别名
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron 解析标准的五字段 Cron 表达式（分 时 日 月 周），按传入时间所在的时区计算执行时间。
+// 字段支持 *、列表、范围、步长以及月份与星期的英文缩写，同时支持 @daily 等别名与 "@every 1m30s"。
+// 日与周同时被限制时，满足其一即执行。
+func ParseCron(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if interval, ok := strings.CutPrefix(expr, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(interval))
+		if err != nil || d <= 0 {
+			return nil, errors.Wrapf(ErrInvalidCron, "Invalid interval in cron expression [%s]", expr)
+		}
+		return Every(d), nil
+	}
+	if strings.HasPrefix(expr, "@") {
+		standard, ok := descriptors[strings.ToLower(expr)]
+		if !ok {
+			return nil, errors.Wrapf(ErrInvalidCron, "Unknown cron descriptor [%s]", expr)
+		}
+		expr = standard
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, errors.Wrapf(ErrInvalidCron, "Cron expression [%s] should have 5 fields", expr)
+	}
+	schedule := &cronSchedule{
+		domStar: fields[2] == "*" || fields[2] == "?",
+		dowStar: fields[4] == "*" || fields[4] == "?",
+	}
+	var err error
+	for i, target := range []struct {
+		field *cronField
+		bits  *uint64
+	}{
+		{&minuteField, &schedule.minute},
+		{&hourField, &schedule.hour},
+		{&domField, &schedule.dom},
+		{&monthField, &schedule.month},
+		{&dowField, &schedule.dow},
+	} {
+		if *target.bits, err = target.field.parse(fields[i]); err != nil {
+			return nil, errors.Wrapf(err, "Parse cron expression [%s] failed", expr)
+		}
+	}
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1 // 7 与 0 均表示星期日
+	}
+	return schedule, nil
+}
+
+// MustParseCron 与 ParseCron 相同，解析失败时抛出 panic
+func MustParseCron(expr string) Schedule {
+	schedule, err := ParseCron(expr)
+	if err != nil {
+		panic(err)
+	}
+	return schedule
+}
+
+// parse 解析 Cron 表达式的一个字段，返回允许取值的位集合
+func (f *cronField) parse(value string) (uint64, error) {
+	result := uint64(0)
+	for _, part := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, errors.Wrapf(ErrInvalidCron, "Invalid step [%s] in %s field", stepPart, f.name)
+			}
+		}
+
+		low, high := f.min, f.max
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			lowPart, highPart, _ := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = f.value(lowPart); err != nil {
+				return 0, err
+			}
+			if high, err = f.value(highPart); err != nil {
+				return 0, err
+			}
+		default:
+			var err error
+			if low, err = f.value(rangePart); err != nil {
+				return 0, err
+			}
+			if !hasStep {
+				high = low
+			}
+		}
+		if low > high {
+			return 0, errors.Wrapf(ErrInvalidCron, "Invalid range [%s] in %s field", rangePart, f.name)
+		}
+		for i := low; i <= high; i += step {
+			result |= 1 << i
+		}
+	}
+	return result, nil
+}
+
+// value 解析字段中的单个取值或英文缩写
+func (f *cronField) value(value string) (int, error) {
+	if n, ok := f.names[strings.ToLower(value)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < f.min || n > f.max {
+		return 0, errors.Wrapf(ErrInvalidCron, "Invalid value [%s] in %s field", value, f.name)
+	}
+	return n, nil
+}
+
+// maxSearchYears 查找下一次执行时间的最大年数，超过后视为不再执行（如 2 月 30 日）
+const maxSearchYears = 5
+
+func (c *cronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+	loc := t.Location()
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			// 跳至本小时内下一个允许的分钟，不存在时进入下一小时
+			rest := c.minute >> uint(t.Minute())
+			if rest == 0 {
+				t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+				continue
+			}
+			t = t.Add(time.Duration(bits.TrailingZeros64(rest)) * time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches 检查日期是否满足日与周字段，两者同时被限制时满足其一即可
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,44 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCron(t *testing.T) {
+	base := time.Date(2024, 1, 31, 10, 17, 30, 0, time.UTC) // 星期三
+	cases := []struct {
+		expr string
+		next time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 31, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 31, 10, 30, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2024, 1, 31, 10, 25, 0, 0, time.UTC)},
+		{"0 9-17 * * mon-fri", time.Date(2024, 1, 31, 11, 0, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2024, 2, 1, 2, 30, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 5", time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC)}, // 日与周满足其一即可
+		{"0 12 * * 7", time.Date(2024, 2, 4, 12, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 1, 31, 11, 0, 0, 0, time.UTC)},
+		{"@every 90s", base.Add(90 * time.Second)},
+	}
+	for _, c := range cases {
+		schedule, err := ParseCron(c.expr)
+		if assert.Nil(t, err, c.expr) {
+			assert.Equal(t, c.next, schedule.Next(base), c.expr)
+		}
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * * foo *", "*/0 * * * *", "5-1 * * * *", "@often", "@every -1s"} {
+		_, err := ParseCron(expr)
+		assert.ErrorIs(t, err, ErrInvalidCron, expr)
+	}
+
+	// 不存在的日期不再执行
+	assert.True(t, MustParseCron("0 0 30 2 *").Next(base).IsZero())
+	assert.True(t, Every(0).Next(base).IsZero())
+}
@@ -0,0 +1,367 @@
+package scheduler
+
+import (
+	"context"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+	"github.com/wolfbolin/bolbox/pkg/log"
+	"github.com/wolfbolin/bolbox/pkg/services"
+)
+
+var (
+	ErrInvalidCron = errors.New("Cron expression is invalid.")
+	ErrInvalidJob  = errors.New("Job is invalid.")
+	ErrJobExists   = errors.New("Job already exists.")
+	ErrJobNotExist = errors.New("Job is not exist.")
+)
+
+// OverlapPolicy 任务到达执行时间时上一次执行尚未结束的处理策略
+type OverlapPolicy int
+
+const (
+	// OverlapSkip 跳过本次执行
+	OverlapSkip OverlapPolicy = iota
+	// OverlapQueue 排队等待上一次执行结束后执行
+	OverlapQueue
+	// OverlapCancel 结束上一次执行的上下文，待其退出后立即执行
+	OverlapCancel
+)
+
+// Job 定时任务
+type Job struct {
+	Name     string
+	Schedule Schedule
+	Run      func(ctx context.Context) error
+	Jitter   time.Duration // 每次执行时间额外增加 [0, Jitter) 的随机延迟
+	Timeout  time.Duration // 单次执行的超时时间，不大于 0 时不限制
+	Overlap  OverlapPolicy
+}
+
+// JobState 定时任务的执行情况
+type JobState struct {
+	Name      string
+	Next      time.Time // 下一次计划执行的时间
+	Running   bool
+	Pending   int       // 排队等待执行的次数
+	Runs      int       // 已完成的执行次数
+	Failures  int       // 返回错误、超时或抛出 panic 的执行次数
+	Skipped   int       // 因上一次执行尚未结束而被跳过的次数
+	Canceled  int       // 因 OverlapCancel 被结束的执行次数，不计入 Failures
+	LastStart time.Time // 最近一次执行的开始时间
+	LastEnd   time.Time // 最近一次执行的结束时间
+	LastErr   error     // 最近一次执行返回的错误
+}
+
+// Scheduler 定时任务调度模块，作为 services.Module 由管理器启动与停止。
+// 任务可以在模块运行前后随时添加或删除，执行结果输出到日志并可通过 Jobs 与 Check 查询，
+// 最近一次执行失败的任务使管理器 Health 报告中该模块不健康并使管理器未就绪，但不影响存活。
+type Scheduler struct {
+	name     string
+	requires []string
+	status   *services.ModuleStatus
+
+	Clock services.Clock // 调度使用的时钟，为空时使用系统时钟
+
+	lock    sync.Mutex
+	jobs    map[string]*jobRunner
+	ctx     context.Context
+	running sync.WaitGroup
+}
+
+var (
+	_ services.Module        = (*Scheduler)(nil)
+	_ services.HealthChecker = (*Scheduler)(nil)
+)
+
+// New 创建一个定时任务调度模块
+func New(name string, requires []string) *Scheduler {
+	return &Scheduler{
+		name:     name,
+		requires: requires,
+		status:   services.NewModuleStatus(),
+		jobs:     make(map[string]*jobRunner),
+	}
+}
+
+// Name 返回模块名称
+func (s *Scheduler) Name() string {
+	return s.name
+}
+
+// Status 返回模块状态
+func (s *Scheduler) Status() *services.ModuleStatus {
+	return s.status
+}
+
+// Requires 返回模块依赖列表
+func (s *Scheduler) Requires() []string {
+	return s.requires
+}
+
+// Add 添加定时任务，模块运行中添加的任务立即开始调度
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" || job.Schedule == nil || job.Run == nil {
+		return errors.Wrapf(ErrInvalidJob, "Job[%s] requires name, schedule and run function", job.Name)
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.jobs[job.Name]; ok {
+		return errors.Wrapf(ErrJobExists, "Job[%s] already exists", job.Name)
+	}
+	runner := &jobRunner{job: job, scheduler: s}
+	s.jobs[job.Name] = runner
+	if s.ctx != nil {
+		s.schedule(runner)
+	}
+	return nil
+}
+
+// AddCron 使用 Cron 表达式添加定时任务
+func (s *Scheduler) AddCron(name, expr string, run func(ctx context.Context) error) error {
+	schedule, err := ParseCron(expr)
+	if err != nil {
+		return err
+	}
+	return s.Add(Job{Name: name, Schedule: schedule, Run: run})
+}
+
+// AddInterval 添加按固定间隔执行的定时任务
+func (s *Scheduler) AddInterval(name string, interval time.Duration, run func(ctx context.Context) error) error {
+	return s.Add(Job{Name: name, Schedule: Every(interval), Run: run})
+}
+
+// Remove 删除定时任务，正在进行的执行将被结束上下文
+func (s *Scheduler) Remove(name string) error {
+	s.lock.Lock()
+	runner, ok := s.jobs[name]
+	delete(s.jobs, name)
+	s.lock.Unlock()
+	if !ok {
+		return errors.Wrapf(ErrJobNotExist, "Job[%s] is not exist", name)
+	}
+	runner.stop()
+	return nil
+}
+
+// Jobs 返回全部定时任务的执行情况（按名称排序）
+func (s *Scheduler) Jobs() []JobState {
+	s.lock.Lock()
+	runners := make([]*jobRunner, 0, len(s.jobs))
+	for _, runner := range s.jobs {
+		runners = append(runners, runner)
+	}
+	s.lock.Unlock()
+
+	result := make([]JobState, 0, len(runners))
+	for _, runner := range runners {
+		result = append(result, runner.snapshot())
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// Check 健康检查函数，最近一次执行失败的任务将使检查失败。
+// 管理器未设置该模块的健康检查时默认以 ReadinessOnly 使用该函数，也可用于 services.HealthCheck 以启用超时与缓存
+func (s *Scheduler) Check(ctx context.Context) error {
+	errs := make([]error, 0)
+	for _, state := range s.Jobs() {
+		if state.LastErr != nil {
+			errs = append(errs, errors.Wrapf(state.LastErr, "Job[%s] failed", state.Name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run 调度全部任务直至上下文结束，并等待正在进行的执行退出
+func (s *Scheduler) Run(ctx context.Context) {
+	s.lock.Lock()
+	s.ctx = ctx
+	for _, runner := range s.jobs {
+		s.schedule(runner)
+	}
+	s.lock.Unlock()
+	s.status.Set(services.StatusRunning)
+	log.Infof("Module[%s] scheduler is running", s.name)
+
+	<-ctx.Done()
+	s.lock.Lock()
+	s.ctx = nil
+	s.lock.Unlock()
+	s.running.Wait()
+	s.status.Set(services.StatusStopped)
+}
+
+func (s *Scheduler) clock() services.Clock {
+	if s.Clock == nil {
+		return services.SystemClock{}
+	}
+	return s.Clock
+}
+
+// schedule 启动任务的调度协程，调用方需持有锁
+func (s *Scheduler) schedule(runner *jobRunner) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	runner.lock.Lock()
+	runner.cancel = cancel
+	runner.lock.Unlock()
+
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		runner.loop(ctx)
+	}()
+}
+
+// jobRunner 调度并执行单个定时任务
+type jobRunner struct {
+	job       Job
+	scheduler *Scheduler
+
+	lock      sync.Mutex
+	cancel    context.CancelFunc
+	state     JobState
+	runCancel context.CancelFunc
+	canceled  bool // 正在进行的执行已被 OverlapCancel 结束
+	executing sync.WaitGroup
+}
+
+// loop 按调度计划触发任务直至上下文结束，并等待正在进行的执行退出
+func (r *jobRunner) loop(ctx context.Context) {
+	defer r.executing.Wait()
+	clock := r.scheduler.clock()
+	for {
+		next := r.job.Schedule.Next(clock.Now())
+		r.lock.Lock()
+		r.state.Next = next
+		r.lock.Unlock()
+		if next.IsZero() {
+			log.Infof("Job[%s] has no more scheduled runs", r.job.Name)
+			return
+		}
+
+		delay := next.Sub(clock.Now())
+		if r.job.Jitter > 0 {
+			delay += rand.N(r.job.Jitter)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-clock.After(delay):
+		}
+		r.trigger(ctx)
+	}
+}
+
+// trigger 按照重叠策略执行任务
+func (r *jobRunner) trigger(ctx context.Context) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if !r.state.Running {
+		r.start(ctx)
+		return
+	}
+
+	switch r.job.Overlap {
+	case OverlapQueue:
+		r.state.Pending += 1
+		log.Infof("Job[%s] is still running, queue the next run. Pending: %d", r.job.Name, r.state.Pending)
+	case OverlapCancel:
+		r.state.Pending = 1
+		r.canceled = true
+		r.runCancel()
+		log.Warnf("Job[%s] is still running, cancel it for the next run", r.job.Name)
+	default:
+		r.state.Skipped += 1
+		log.Warnf("Job[%s] is still running, skip this run. Skipped: %d", r.job.Name, r.state.Skipped)
+	}
+}
+
+// start 开始一次执行，调用方需持有锁
+func (r *jobRunner) start(ctx context.Context) {
+	var runCtx context.Context
+	var cancel context.CancelFunc
+	if r.job.Timeout > 0 {
+		runCtx, cancel = context.WithTimeout(ctx, r.job.Timeout)
+	} else {
+		runCtx, cancel = context.WithCancel(ctx)
+	}
+	r.state.Running, r.runCancel = true, cancel
+	r.state.LastStart = r.scheduler.clock().Now()
+
+	r.executing.Add(1)
+	go func() {
+		defer r.executing.Done()
+		err := r.execute(runCtx)
+		cancel()
+		r.finish(ctx, err)
+	}()
+}
+
+// execute 执行任务函数，将 panic 与超时转换为错误
+func (r *jobRunner) execute(ctx context.Context) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = errors.Errorf("Job[%s] throws a panic. %+v", r.job.Name, recovered)
+		}
+	}()
+	err = r.job.Run(ctx)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		if err == nil {
+			err = ctx.Err()
+		}
+		err = errors.Wrapf(err, "Job[%s] exceeds timeout %s", r.job.Name, r.job.Timeout)
+	}
+	return err
+}
+
+// finish 记录执行结果，并在存在排队的执行时开始下一次执行。
+// 被 OverlapCancel 主动结束并返回 context.Canceled 的执行不视为失败，也不改变 LastErr
+func (r *jobRunner) finish(ctx context.Context, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	canceled := r.canceled && errors.Is(err, context.Canceled)
+	r.state.Running, r.canceled = false, false
+	r.state.Runs += 1
+	r.state.LastEnd = r.scheduler.clock().Now()
+	elapsed := r.state.LastEnd.Sub(r.state.LastStart)
+	switch {
+	case canceled:
+		r.state.Canceled += 1
+		log.Infof("Job[%s] is canceled for the next run after %s", r.job.Name, elapsed)
+	case err != nil:
+		r.state.LastErr = err
+		r.state.Failures += 1
+		log.Errorf("Job[%s] failed after %s. %+v", r.job.Name, elapsed, err)
+	default:
+		r.state.LastErr = nil
+		log.Infof("Job[%s] finished in %s", r.job.Name, elapsed)
+	}
+
+	if r.state.Pending > 0 && ctx.Err() == nil {
+		r.state.Pending -= 1
+		r.start(ctx)
+	}
+}
+
+// stop 结束任务的调度与正在进行的执行
+func (r *jobRunner) stop() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.cancel != nil {
+		r.cancel()
+	}
+}
+
+func (r *jobRunner) snapshot() JobState {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	state := r.state
+	state.Name = r.job.Name
+	return state
+}
@@ -0,0 +1,180 @@
+package scheduler
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+	"github.com/wolfbolin/bolbox/pkg/services"
+	"github.com/wolfbolin/bolbox/pkg/services/servicestest"
+)
+
+func startScheduler(t *testing.T, jobs ...Job) (*Scheduler, *servicestest.FakeClock) {
+	clock := servicestest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := New("scheduler", nil)
+	s.Clock = clock
+	for _, job := range jobs {
+		assert.Nil(t, s.Add(job))
+	}
+
+	ctx, stop := context.WithCancel(context.TODO())
+	changed := s.Status().Changed()
+	go s.Run(ctx)
+	<-changed
+	t.Cleanup(func() {
+		changed := s.Status().Changed()
+		stop()
+		<-changed
+		assert.Equal(t, services.StatusStopped, s.Status().Get())
+	})
+	return s, clock
+}
+
+func waitJob(t *testing.T, s *Scheduler, check func(state JobState) bool) {
+	assert.Eventually(t, func() bool {
+		return check(s.Jobs()[0])
+	}, time.Second, time.Millisecond)
+}
+
+func TestSchedulerInterval(t *testing.T) {
+	runs := atomic.Int32{}
+	s, clock := startScheduler(t, Job{Name: "tick", Schedule: Every(time.Minute), Run: func(ctx context.Context) error {
+		if runs.Add(1) == 2 {
+			return errors.New("disk full")
+		}
+		return nil
+	}})
+
+	for i := 1; i <= 3; i++ {
+		assert.True(t, clock.BlockUntil(1, time.Second))
+		clock.Advance(time.Minute)
+		waitJob(t, s, func(state JobState) bool { return state.Runs == i })
+		if i == 2 {
+			assert.ErrorContains(t, s.Check(context.TODO()), "disk full")
+		}
+	}
+	state := s.Jobs()[0]
+	assert.Equal(t, 1, state.Failures)
+	assert.Nil(t, state.LastErr)
+	assert.Nil(t, s.Check(context.TODO()))
+}
+
+func TestSchedulerOverlap(t *testing.T) {
+	for _, c := range []struct {
+		policy  OverlapPolicy
+		runs    int
+		skipped int
+	}{
+		{OverlapSkip, 1, 1},
+		{OverlapQueue, 2, 0},
+		{OverlapCancel, 2, 0},
+	} {
+		release := make(chan struct{})
+		started := make(chan struct{}, 2)
+		s, clock := startScheduler(t, Job{Name: "slow", Schedule: Every(time.Minute), Overlap: c.policy,
+			Run: func(ctx context.Context) error {
+				started <- struct{}{}
+				select {
+				case <-release:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}})
+
+		assert.True(t, clock.BlockUntil(1, time.Second))
+		clock.Advance(time.Minute)
+		<-started
+		assert.True(t, clock.BlockUntil(1, time.Second))
+		clock.Advance(time.Minute)
+		if c.policy == OverlapSkip {
+			waitJob(t, s, func(state JobState) bool { return state.Skipped == 1 })
+		}
+		if c.policy == OverlapCancel {
+			<-started // 上一次执行被结束后立即开始下一次执行
+		}
+		close(release)
+		waitJob(t, s, func(state JobState) bool { return state.Runs == c.runs && !state.Running })
+		assert.Equal(t, c.skipped, s.Jobs()[0].Skipped, c.policy)
+		// 被 OverlapCancel 结束的执行不计为失败
+		assert.Equal(t, 0, s.Jobs()[0].Failures, c.policy)
+		assert.Nil(t, s.Check(context.TODO()), c.policy)
+		if c.policy == OverlapCancel {
+			assert.Equal(t, 1, s.Jobs()[0].Canceled)
+		}
+	}
+}
+
+func TestSchedulerTimeoutAndPanic(t *testing.T) {
+	s, clock := startScheduler(t,
+		Job{Name: "a-timeout", Schedule: Every(time.Minute), Timeout: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}},
+		Job{Name: "b-panic", Schedule: Every(time.Minute), Run: func(ctx context.Context) error {
+			panic("boom")
+		}},
+	)
+	assert.True(t, clock.BlockUntil(2, time.Second))
+	clock.Advance(time.Minute)
+	assert.Eventually(t, func() bool {
+		jobs := s.Jobs()
+		return jobs[0].Failures == 1 && jobs[1].Failures == 1
+	}, time.Second, time.Millisecond)
+
+	jobs := s.Jobs()
+	assert.ErrorIs(t, jobs[0].LastErr, context.DeadlineExceeded)
+	assert.ErrorContains(t, jobs[1].LastErr, "boom")
+}
+
+func TestSchedulerAddRemove(t *testing.T) {
+	s, clock := startScheduler(t)
+	assert.ErrorIs(t, s.Add(Job{Name: "invalid"}), ErrInvalidJob)
+	assert.ErrorIs(t, s.AddCron("bad", "* *", func(ctx context.Context) error { return nil }), ErrInvalidCron)
+	assert.Nil(t, s.AddCron("hourly", "@hourly", func(ctx context.Context) error { return nil }))
+	assert.ErrorIs(t, s.AddInterval("hourly", time.Hour, func(ctx context.Context) error { return nil }), ErrJobExists)
+
+	assert.True(t, clock.BlockUntil(1, time.Second))
+	assert.Equal(t, time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC), s.Jobs()[0].Next)
+	clock.Advance(time.Hour)
+	waitJob(t, s, func(state JobState) bool { return state.Runs == 1 })
+
+	assert.Nil(t, s.Remove("hourly"))
+	assert.ErrorIs(t, s.Remove("hourly"), ErrJobNotExist)
+	assert.Empty(t, s.Jobs())
+}
+
+func TestSchedulerHealth(t *testing.T) {
+	fail := atomic.Bool{}
+	fail.Store(true)
+	s, clock := startScheduler(t, Job{Name: "sync", Schedule: Every(time.Minute), Run: func(ctx context.Context) error {
+		if fail.Load() {
+			return errors.New("upstream unavailable")
+		}
+		return nil
+	}})
+	mgr := services.NewManager()
+	assert.Nil(t, mgr.AddModule("scheduler", s))
+
+	// 未设置健康检查时，任务的执行结果体现在模块的健康状态上
+	assert.True(t, clock.BlockUntil(1, time.Second))
+	clock.Advance(time.Minute)
+	waitJob(t, s, func(state JobState) bool { return state.Runs == 1 })
+	// 任务失败仅使模块未就绪，不影响存活
+	report := mgr.Health(context.TODO())
+	assert.True(t, report.Live)
+	assert.False(t, report.Modules[0].Healthy)
+	assert.Contains(t, report.Modules[0].Error, "upstream unavailable")
+
+	fail.Store(false)
+	assert.True(t, clock.BlockUntil(1, time.Second))
+	clock.Advance(time.Minute)
+	waitJob(t, s, func(state JobState) bool { return state.Runs == 2 })
+	report = mgr.Health(context.TODO())
+	assert.True(t, report.Live)
+	assert.True(t, report.Modules[0].Healthy)
+}
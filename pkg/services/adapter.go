@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+	"github.com/wolfbolin/bolbox/pkg/log"
+)
+
+// ErrorReporter 可选接口，模块通过其报告运行结束时产生的错误，管理器将其附带在 EventFailed 事件中
+type ErrorReporter interface {
+	Err() error
+}
+
+// FuncModule 基于函数实现的模块，自动管理模块状态的切换并记录运行函数返回的错误
+type FuncModule struct {
+	name     string
+	requires []string
+	status   *ModuleStatus
+	run      func(ctx context.Context, ready func()) error
+
+	lock sync.Mutex
+	err  error
+}
+
+var (
+	_ Module        = (*FuncModule)(nil)
+	_ ErrorReporter = (*FuncModule)(nil)
+)
+
+// NewFuncModule 创建一个函数模块，模块在调用 run 时切换为运行状态，在 run 返回后切换为停止状态
+func NewFuncModule(name string, requires []string, run func(ctx context.Context) error) *FuncModule {
+	return newFuncModule(name, requires, func(ctx context.Context, ready func()) error {
+		ready()
+		return run(ctx)
+	})
+}
+
+// newFuncModule 创建一个函数模块，模块在 run 调用 ready 时切换为运行状态
+func newFuncModule(name string, requires []string, run func(ctx context.Context, ready func()) error) *FuncModule {
+	return &FuncModule{
+		name:     name,
+		requires: requires,
+		status:   NewModuleStatus(),
+		run:      run,
+	}
+}
+
+// Name 返回模块名称
+func (f *FuncModule) Name() string {
+	return f.name
+}
+
+// Status 返回模块状态
+func (f *FuncModule) Status() *ModuleStatus {
+	return f.status
+}
+
+// Requires 返回模块依赖列表
+func (f *FuncModule) Requires() []string {
+	return f.requires
+}
+
+// Run 运行模块函数直至其返回，并记录返回的错误
+func (f *FuncModule) Run(ctx context.Context) {
+	f.lock.Lock()
+	f.err = nil
+	f.lock.Unlock()
+
+	once := sync.Once{}
+	err := f.run(ctx, func() {
+		once.Do(func() {
+			f.status.Set(StatusRunning)
+		})
+	})
+	if err != nil {
+		log.Errorf("Module[%s] exited with error. %+v", f.name, err)
+	}
+
+	f.lock.Lock()
+	f.err = err
+	f.lock.Unlock()
+	f.status.Set(StatusStopped)
+}
+
+// Err 返回模块函数最近一次运行返回的错误
+func (f *FuncModule) Err() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.err
+}
+
+// NewHTTPServerModule 创建一个运行 HTTP 服务的模块。
+// 模块在监听端口成功后切换为运行状态，上下文结束时最多等待 shutdownTimeout 优雅关闭服务。
+func NewHTTPServerModule(name string, requires []string, server *http.Server, shutdownTimeout time.Duration) *FuncModule {
+	return newFuncModule(name, requires, func(ctx context.Context, ready func()) error {
+		addr := server.Addr
+		if addr == "" {
+			addr = ":http"
+		}
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return errors.Wrapf(err, "Listen http server on [%s] failed", addr)
+		}
+		ready()
+		log.Infof("Module[%s] http server is listening on [%s]", name, listener.Addr())
+
+		serveErr := make(chan error, 1)
+		go func() {
+			serveErr <- server.Serve(listener)
+		}()
+
+		select {
+		case err = <-serveErr:
+			return errors.Wrapf(err, "Http server exited unexpectedly")
+		case <-ctx.Done():
+		}
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err = server.Shutdown(shutdownCtx); err != nil {
+			return errors.Wrapf(err, "Shutdown http server failed")
+		}
+		if err = <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return errors.WithStack(err)
+		}
+		return nil
+	})
+}
+
+// NewTickerModule 创建一个按固定间隔执行任务的模块，任务返回的错误仅被记录，不会中断模块运行
+func NewTickerModule(name string, requires []string, interval time.Duration, job func(ctx context.Context) error) *FuncModule {
+	return NewFuncModule(name, requires, func(ctx context.Context) error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				if err := job(ctx); err != nil {
+					log.Errorf("Module[%s] ticker job failed. %+v", name, err)
+				}
+			}
+		}
+	})
+}
+
+// NewConsumerModule 创建一个由 workers 个协程并发消费 source 的模块。
+// 处理函数返回的错误仅被记录；上下文结束或 source 被关闭后，模块在全部协程退出时停止运行。
+func NewConsumerModule[T any](name string, requires []string, source <-chan T, workers int, handle func(ctx context.Context, item T) error) *FuncModule {
+	return NewFuncModule(name, requires, func(ctx context.Context) error {
+		wg := sync.WaitGroup{}
+		for range max(workers, 1) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case item, ok := <-source:
+						if !ok {
+							return
+						}
+						if err := handle(ctx, item); err != nil {
+							log.Errorf("Module[%s] consume item failed. %+v", name, err)
+						}
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		return nil
+	})
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+)
+
+func TestFuncModule(t *testing.T) {
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+
+	mod := NewFuncModule("func", []string{"db"}, func(ctx context.Context) error {
+		<-ctx.Done()
+		return errors.New("flush failed")
+	})
+	assert.Equal(t, "func", mod.Name())
+	assert.Equal(t, []string{"db"}, mod.Requires())
+
+	changed := mod.Status().Changed()
+	go mod.Run(ctx)
+	<-changed
+	assert.Equal(t, StatusRunning, mod.Status().Get())
+	assert.Nil(t, mod.Err())
+
+	stop()
+	assert.Nil(t, waitStopped(context.TODO(), mod.Status()))
+	assert.EqualError(t, mod.Err(), "flush failed")
+}
+
+func TestNewHTTPServerModule(t *testing.T) {
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+
+	server := &http.Server{
+		Addr: "127.0.0.1:0",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("pong"))
+		}),
+	}
+	mod := NewHTTPServerModule("http", nil, server, time.Second)
+	changed := mod.Status().Changed()
+	go mod.Run(ctx)
+	<-changed
+	assert.Equal(t, StatusRunning, mod.Status().Get())
+
+	stop()
+	assert.Nil(t, waitStopped(context.TODO(), mod.Status()))
+	assert.Nil(t, mod.Err())
+
+	// 端口被占用时模块直接停止并报告错误
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+	busy := NewHTTPServerModule("busy", nil, &http.Server{Addr: listener.Addr().String()}, time.Second)
+	busy.Run(context.TODO())
+	assert.ErrorContains(t, busy.Err(), "address already in use")
+	assert.Equal(t, StatusStopped, busy.Status().Get())
+}
+
+func TestNewHTTPServerModule_startFailed(t *testing.T) {
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+
+	lock := sync.Mutex{}
+	records := make([]string, 0)
+	record := func(event Event) error {
+		lock.Lock()
+		defer lock.Unlock()
+		records = append(records, string(event.Type)+":"+event.Module)
+		return nil
+	}
+	mgr := NewManager()
+	mgr.Options.FailFast = false
+	mgr.BeforeStart(record)
+	mgr.AfterStart(record)
+	mgr.AfterStop(record)
+	mgr.OnFailure(record)
+	assert.Nil(t, mgr.AddModule("busy", NewHTTPServerModule("busy", nil, &http.Server{Addr: listener.Addr().String()}, time.Second)))
+	mgr.ctx = ctx
+	mgr.startAll()
+
+	// 未能监听端口的服务不会被视为启动成功
+	assert.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(records) == 3
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"starting:busy", "failed:busy", "stopped:busy"}, records)
+}
+
+func TestNewTickerModule(t *testing.T) {
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+
+	count := atomic.Int32{}
+	mod := NewTickerModule("ticker", nil, 5*time.Millisecond, func(ctx context.Context) error {
+		count.Add(1)
+		return errors.New("ignored")
+	})
+	go mod.Run(ctx)
+	assert.Eventually(t, func() bool {
+		return count.Load() >= 3
+	}, time.Second, 5*time.Millisecond)
+	stop()
+	assert.Nil(t, waitStopped(context.TODO(), mod.Status()))
+	assert.Nil(t, mod.Err())
+}
+
+func TestNewConsumerModule(t *testing.T) {
+	source := make(chan int, 10)
+	for i := range 10 {
+		source <- i
+	}
+	close(source)
+
+	sum := atomic.Int32{}
+	mod := NewConsumerModule("consumer", nil, source, 3, func(ctx context.Context, item int) error {
+		sum.Add(int32(item))
+		return nil
+	})
+	mod.Run(context.TODO())
+	assert.Equal(t, int32(45), sum.Load())
+	assert.Equal(t, StatusStopped, mod.Status().Get())
+}
+
+func TestManager_failure_error(t *testing.T) {
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+
+	mgr := NewManager()
+	events, cancel := mgr.Subscribe(16)
+	defer cancel()
+	assert.Nil(t, mgr.AddModule("job", NewFuncModule("job", nil, func(ctx context.Context) error {
+		time.Sleep(10 * time.Millisecond)
+		return errors.New("job failed")
+	})))
+	mgr.ctx = ctx
+	mgr.startAll()
+
+	for event := range events {
+		if event.Type == EventFailed {
+			assert.EqualError(t, event.Err, "job failed")
+			break
+		}
+	}
+}
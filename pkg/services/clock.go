@@ -0,0 +1,28 @@
+package services
+
+import "time"
+
+// Clock 时钟接口，管理器通过其获取时间与等待超时，便于在测试中替换为可控的时钟
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock 基于系统时间的时钟，管理器与调度器未设置时钟时使用
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (SystemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// clock 返回管理器使用的时钟，未设置时使用系统时钟
+func (m *Manager) clock() Clock {
+	if clock := m.options().Clock; clock != nil {
+		return clock
+	}
+	return SystemClock{}
+}
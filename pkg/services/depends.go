@@ -0,0 +1,188 @@
+package services
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// GroupPrefix Requires 中以该前缀开头的条目表示依赖分组中全部启用的模块
+const GroupPrefix = "@"
+
+// OptionalRequirer 可选接口，声明模块的可选依赖。
+// 可选依赖仅在目标模块已添加且启用时生效，生效后与 Requires 中的依赖相同。
+type OptionalRequirer interface {
+	OptionalRequires() []string
+}
+
+// AfterHinter 可选接口，声明模块的启动顺序提示。
+// 目标模块已添加且启用时，本模块在其之后的批次中启动，但不要求目标模块进入运行状态。
+type AfterHinter interface {
+	After() []string
+}
+
+// Grouper 可选接口，声明模块所属的分组。
+// 分组可以通过 "@分组名" 被整体依赖，也可以通过 DisableGroups 整体禁用。
+type Grouper interface {
+	Groups() []string
+}
+
+// DisableGroups 禁用指定分组中的全部模块，被禁用的模块不会被启动，在下一次启动或添加模块时生效
+func (m *Manager) DisableGroups(groups ...string) {
+	m.mapLock.Lock()
+	defer m.mapLock.Unlock()
+	if m.Options == nil {
+		m.Options = DefaultOptions()
+	}
+	for _, group := range groups {
+		if !slices.Contains(m.Options.DisabledGroups, group) {
+			m.Options.DisabledGroups = append(m.Options.DisabledGroups, group)
+		}
+	}
+}
+
+// EnableGroups 重新启用被禁用的分组，在下一次启动或添加模块时生效
+func (m *Manager) EnableGroups(groups ...string) {
+	m.mapLock.Lock()
+	defer m.mapLock.Unlock()
+	if m.Options == nil {
+		return
+	}
+	m.Options.DisabledGroups = slices.DeleteFunc(m.Options.DisabledGroups, func(group string) bool {
+		return slices.Contains(groups, group)
+	})
+}
+
+// moduleSet 当前角色与分组设置下启用的模块集合。
+// 角色闭包在创建时计算一次，供同一次持有锁期间的多次查询使用，模块映射或选项变更后需重新创建
+type moduleSet struct {
+	m       *Manager
+	closure map[string]bool // 属于当前角色的模块及其依赖闭包，未设置角色时为 nil
+}
+
+// modules 返回当前启用的模块集合，调用方需持有锁
+func (m *Manager) modules() *moduleSet {
+	return &moduleSet{m: m, closure: m.roleClosure()}
+}
+
+// enabled 判断模块是否已添加、所属分组均未被禁用且属于当前角色的依赖闭包
+func (s *moduleSet) enabled(name string) bool {
+	if !s.m.groupEnabled(name) {
+		return false
+	}
+	return s.closure == nil || s.closure[name]
+}
+
+// groupEnabled 判断模块是否已添加且所属分组均未被禁用，调用方需持有锁
+func (m *Manager) groupEnabled(name string) bool {
+	module, ok := m.moduleMap[name]
+	if !ok {
+		return false
+	}
+	grouper, ok := module.(Grouper)
+	if !ok {
+		return true
+	}
+	for _, group := range grouper.Groups() {
+		if slices.Contains(m.options().DisabledGroups, group) {
+			return false
+		}
+	}
+	return true
+}
+
+// enabledModules 返回全部启用的模块名称（有序）
+func (s *moduleSet) enabledModules() []string {
+	names := make([]string, 0, len(s.m.moduleMap))
+	for name := range s.m.moduleMap {
+		if s.enabled(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// disabledModules 返回全部被分组或角色禁用的模块名称（有序），调用方需持有锁
+func (m *Manager) disabledModules() []string {
+	set := m.modules()
+	names := make([]string, 0)
+	for name := range m.moduleMap {
+		if !set.enabled(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// groupMembers 返回分组中除 except 以外满足 filter 的模块名称（有序），调用方需持有锁
+func (m *Manager) groupMembers(group string, except string, filter func(name string) bool) []string {
+	members := make([]string, 0)
+	for name, module := range m.moduleMap {
+		grouper, ok := module.(Grouper)
+		if !ok || name == except || !filter(name) {
+			continue
+		}
+		if slices.Contains(grouper.Groups(), group) {
+			members = append(members, name)
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
+// hardRequiresOf 返回模块在 Requires 中声明的依赖，分组依赖展开为分组中启用的模块，不包括可选依赖
+func (s *moduleSet) hardRequiresOf(name string) []string {
+	module := s.m.moduleMap[name]
+	requires := make([]string, 0, len(module.Requires()))
+	for _, depMod := range module.Requires() {
+		if group, ok := strings.CutPrefix(depMod, GroupPrefix); ok {
+			requires = append(requires, s.m.groupMembers(group, name, s.enabled)...)
+			continue
+		}
+		requires = append(requires, depMod)
+	}
+	return uniqueNames(requires)
+}
+
+// requiresOf 返回模块需要等待其运行的全部依赖，包括展开后的分组依赖与生效的可选依赖
+func (s *moduleSet) requiresOf(name string) []string {
+	module := s.m.moduleMap[name]
+	requires := s.hardRequiresOf(name)
+	if optional, ok := module.(OptionalRequirer); ok {
+		for _, depMod := range optional.OptionalRequires() {
+			if depMod != name && s.enabled(depMod) {
+				requires = append(requires, depMod)
+			}
+		}
+	}
+	return uniqueNames(requires)
+}
+
+// startAfter 返回模块需要在其之后启动的全部模块，即 requiresOf 与生效的启动顺序提示
+func (s *moduleSet) startAfter(name string) []string {
+	after := s.requiresOf(name)
+	if hinter, ok := s.m.moduleMap[name].(AfterHinter); ok {
+		for _, depMod := range hinter.After() {
+			if depMod != name && s.enabled(depMod) {
+				after = append(after, depMod)
+			}
+		}
+	}
+	return uniqueNames(after)
+}
+
+// uniqueNames 按首次出现的顺序去除重复的名称
+func uniqueNames(names []string) []string {
+	seen := make(map[string]struct{}, len(names))
+	unique := make([]string, 0, len(names))
+	for _, name := range names {
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		unique = append(unique, name)
+	}
+	return unique
+}
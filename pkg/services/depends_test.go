@@ -0,0 +1,71 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+)
+
+type softModule struct {
+	fakeModule
+	optional []string
+	after    []string
+	groups   []string
+}
+
+func (s *softModule) OptionalRequires() []string {
+	return s.optional
+}
+
+func (s *softModule) After() []string {
+	return s.after
+}
+
+func (s *softModule) Groups() []string {
+	return s.groups
+}
+
+func TestManager_checkAndWave_soft(t *testing.T) {
+	mgr := NewManager()
+	mgr.AddModule("tracing", &softModule{fakeModule: fakeModule{name: "tracing"}, groups: []string{"observe"}})
+	mgr.AddModule("logging", &softModule{fakeModule: fakeModule{name: "logging"}, groups: []string{"observe"}})
+	mgr.AddModule("metrics", &softModule{
+		fakeModule: fakeModule{name: "metrics"},
+		optional:   []string{"tracing", "profiling"},
+		after:      []string{"logging", "absent"},
+	})
+	mgr.AddModule("api", &fakeModule{name: "api", requires: []string{"@observe"}})
+
+	waves, err := mgr.checkAndWave()
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"logging", "tracing"}, {"api", "metrics"}}, waves)
+
+	// 禁用分组后可选依赖与启动顺序提示不再生效
+	mgr.DisableGroups("observe")
+	assert.Equal(t, []string{"observe"}, mgr.Options.DisabledGroups)
+	_, err = mgr.checkAndWave()
+	assert.True(t, errors.Is(err, ErrMissingDependency))
+
+	mgr.AddModule("api", &fakeModule{name: "api", requires: []string{"tracing"}})
+	_, err = mgr.checkAndWave()
+	assert.True(t, errors.Is(err, ErrModuleDisabled))
+
+	mgr.AddModule("api", &fakeModule{name: "api"})
+	waves, err = mgr.checkAndWave()
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"api", "metrics"}}, waves)
+
+	mgr.EnableGroups("observe")
+	assert.Empty(t, mgr.Options.DisabledGroups)
+}
+
+func TestManager_checkAndWave_softCyclic(t *testing.T) {
+	mgr := NewManager()
+	mgr.AddModule("A", &softModule{fakeModule: fakeModule{name: "A"}, after: []string{"B"}})
+	mgr.AddModule("B", &softModule{fakeModule: fakeModule{name: "B"}, optional: []string{"A"}})
+	_, err := mgr.checkAndWave()
+	assert.True(t, errors.Is(err, ErrCyclicDependency))
+	assert.Contains(t, err.Error(), "A -> B -> A")
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+	"github.com/wolfbolin/bolbox/pkg/log"
+)
+
+// AddModule 将新模块添加到管理器的模块映射中，使用锁确保线程安全。
+// 若管理器已经启动，模块将在其依赖全部进入运行状态后自动启动。
+func (m *Manager) AddModule(name string, module Module) error {
+	m.mapLock.Lock()
+	defer m.mapLock.Unlock()
+	if !m.started.Load() {
+		m.moduleMap[name] = module
+		return nil
+	}
+
+	if _, ok := m.moduleMap[name]; ok {
+		return errors.Wrapf(ErrModuleExists, "Module[%s] has been added to running manager", name)
+	}
+	for _, depMod := range module.Requires() {
+		if depMod == name {
+			return errors.Wrapf(ErrSelfDependency, "Module[%s] requires itself", name)
+		}
+	}
+	m.moduleMap[name] = module
+	set := m.modules()
+	if cycle := m.findCycle(set, []string{name}); cycle != nil {
+		delete(m.moduleMap, name)
+		return errors.Wrapf(ErrCyclicDependency, "Module has cyclic dependencies [%s]", strings.Join(cycle, " -> "))
+	}
+	m.notifyModules()
+	if !set.enabled(name) {
+		log.Infof("Module[%s] is added to running manager but has been disabled", name)
+		return nil
+	}
+
+	log.Infof("Module[%s] is added to running manager and waits for requires%v", name, set.requiresOf(name))
+	m.watchStart(name)
+	return nil
+}
+
+// watchStart 为模块分配添加序号，并在其依赖全部进入运行状态后启动该模块，调用方需持有写锁
+func (m *Manager) watchStart(name string) {
+	m.generation += 1
+	if m.genMap == nil {
+		m.genMap = make(map[string]uint64)
+	}
+	m.genMap[name] = m.generation
+	go m.hotStart(name, m.generation)
+}
+
+// DelModule 从管理器中删除模块，若存在依赖该模块的其他模块则拒绝删除。
+// 若管理器已经启动，模块将被通知退出，并最多等待 StopTimeout 直至其停止运行。
+func (m *Manager) DelModule(name string) error {
+	_, err := m.delModule(name, false)
+	return err
+}
+
+// DelModuleCascade 从管理器中删除模块以及全部直接或间接依赖该模块的模块。
+// 依赖方先于被依赖方停止，返回按停止顺序排列的被删除模块列表。
+func (m *Manager) DelModuleCascade(name string) ([]string, error) {
+	return m.delModule(name, true)
+}
+
+func (m *Manager) delModule(name string, cascade bool) ([]string, error) {
+	removed, targets, err := m.detachModules(name, cascade)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, 0)
+	for _, target := range targets {
+		if err := m.stopModule(target); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	log.Infof("Modules%v have been deleted from manager", removed)
+	return removed, errors.Join(errs...)
+}
+
+// detachModules 在持有写锁期间将模块及依赖方从映射中移除，返回被删除的模块与需要等待停止的运行中模块。
+// 未运行的模块直接取消其上下文，运行中的模块由调用方在释放锁之后依次停止。
+func (m *Manager) detachModules(name string, cascade bool) ([]string, []stopTarget, error) {
+	m.mapLock.Lock()
+	defer m.mapLock.Unlock()
+	if _, ok := m.moduleMap[name]; !ok {
+		return nil, nil, errors.Wrapf(ErrModuleNotExist, "Module[%s] has not been added", name)
+	}
+
+	dependents := m.dependents(m.modules(), name)
+	if len(dependents) != 0 && !cascade {
+		return nil, nil, errors.Wrapf(ErrModuleRequired, "Module[%s] is required by modules%v", name, dependents)
+	}
+
+	removed := append(dependents, name)
+	targets := make([]stopTarget, 0, len(removed))
+	for _, modName := range removed {
+		if cancel, ok := m.cancelMap[modName]; ok {
+			modStatus := m.moduleMap[modName].Status()
+			if modStatus != nil && modStatus.Get() == StatusRunning {
+				targets = append(targets, stopTarget{name: modName, status: modStatus, cancel: cancel})
+			} else {
+				cancel()
+			}
+		}
+		delete(m.moduleMap, modName)
+		delete(m.genMap, modName)
+		delete(m.healthMap, modName)
+		delete(m.contextMap, modName)
+		delete(m.cancelMap, modName)
+		m.stats.remove(modName)
+	}
+	m.notifyModules()
+	return removed, targets, nil
+}
+
+// dependents 返回直接或间接依赖指定模块的全部模块，依赖方排列在被依赖方之前。
+// 仅计入 Requires 中声明的依赖与分组依赖，可选依赖的目标被删除后依赖方可以继续运行。
+func (m *Manager) dependents(set *moduleSet, name string) []string {
+	depMap := make(map[string][]string) // 节点被外部依赖的列表
+	for modName := range m.moduleMap {
+		for _, depMod := range set.hardRequiresOf(modName) {
+			depMap[depMod] = append(depMap[depMod], modName)
+		}
+	}
+
+	visited := map[string]bool{name: true}
+	result := make([]string, 0)
+	var visit func(name string)
+	visit = func(name string) {
+		sort.Strings(depMap[name])
+		for _, modName := range depMap[name] {
+			if visited[modName] {
+				continue
+			}
+			visited[modName] = true
+			visit(modName)
+			result = append(result, modName)
+		}
+	}
+	visit(name)
+	return result
+}
+
+// stopModule 取消已从映射中移除的模块的运行上下文，并最多等待 StopTimeout 直至其停止运行
+func (m *Manager) stopModule(target stopTarget) error {
+	log.Infof("Module[%s] is deleted from manager. Notify it to gracefully exit", target.name)
+	_ = m.emit(Event{Type: EventStopping, Module: target.name})
+	target.cancel()
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	go func() {
+		select {
+		case <-m.clock().After(m.options().StopTimeout):
+			stop()
+		case <-ctx.Done():
+		}
+	}()
+	if err := waitStopped(ctx, target.status); err != nil {
+		return errors.Wrapf(ErrStopTimeout, "Module[%s] has not stopped after %s", target.name, m.options().StopTimeout)
+	}
+	log.Warnf("Module[%s] has gracefully exited", target.name)
+	return nil
+}
+
+// hotStart 等待已启动管理器中新增模块的依赖全部进入运行状态后启动该模块，
+// 模块在启动前被删除或以同名模块替换时（generation 不再匹配）放弃启动
+func (m *Manager) hotStart(name string, generation uint64) {
+	for {
+		m.mapLock.Lock()
+		if gen, ok := m.genMap[name]; !ok || gen != generation {
+			m.mapLock.Unlock()
+			log.Infof("Module[%s] has been deleted before started", name)
+			return
+		}
+		set := m.modules()
+		depWait, mapWait := m.waitRequires(set, name), m.modulesChanged()
+		if depWait == nil {
+			start := m.prepareModule(set, name, m.options().StartTimeout)
+			m.mapLock.Unlock()
+			if start == nil {
+				return
+			}
+			if err := start(); err != nil {
+				log.Errorf("Start function module failed. %+v", err)
+			}
+			return
+		}
+		m.mapLock.Unlock()
+
+		select {
+		case <-depWait:
+		case <-mapWait:
+		case <-m.ctx.Done():
+			return
+		}
+	}
+}
+
+// waitRequires 返回一个在模块未就绪的依赖发生变化时关闭的通道，依赖全部运行时返回 nil。
+// 无法获取状态的依赖视为已就绪，与启动时的处理保持一致；启动顺序提示不需要等待。
+func (m *Manager) waitRequires(set *moduleSet, name string) <-chan struct{} {
+	for _, depMod := range set.requiresOf(name) {
+		dep, ok := m.moduleMap[depMod]
+		if !ok {
+			return m.modulesChanged()
+		}
+		depStatus := dep.Status()
+		if depStatus == nil {
+			continue
+		}
+		changed := depStatus.Changed()
+		if depStatus.Get() != StatusRunning {
+			return changed
+		}
+	}
+	return nil
+}
+
+// modulesChanged 返回一个在模块映射下一次变更时关闭的通道，调用方需持有写锁
+func (m *Manager) modulesChanged() <-chan struct{} {
+	if m.mapChanged == nil {
+		m.mapChanged = make(chan struct{})
+	}
+	return m.mapChanged
+}
+
+// notifyModules 通知模块映射发生变更，调用方需持有写锁
+func (m *Manager) notifyModules() {
+	if m.mapChanged != nil {
+		close(m.mapChanged)
+		m.mapChanged = nil
+	}
+}
+
+// waitStopped 等待模块离开运行状态，直至上下文结束
+func waitStopped(ctx context.Context, modStatus *ModuleStatus) error {
+	for changed := modStatus.Changed(); modStatus.Get() == StatusRunning; changed = modStatus.Changed() {
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
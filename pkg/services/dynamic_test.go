@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+)
+
+func newServingModule(name string, requires ...string) *fakeModule {
+	mod := &fakeModule{
+		name:     name,
+		status:   NewModuleStatus(),
+		requires: requires,
+	}
+	mod.run = func(ctx context.Context) {
+		mod.status.Set(StatusRunning)
+		<-ctx.Done()
+		mod.status.Set(StatusStopped)
+	}
+	return mod
+}
+
+func waitRunning(t *testing.T, mod *fakeModule) {
+	assert.Eventually(t, func() bool {
+		return mod.status.Get() == StatusRunning
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestManager_AddModule_running(t *testing.T) {
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+
+	mgr := NewManager()
+	base := newServingModule("base")
+	assert.Nil(t, mgr.AddModule("base", base))
+	go mgr.StartAndServe(ctx)
+	waitRunning(t, base)
+	assert.Eventually(t, mgr.started.Load, time.Second, 5*time.Millisecond)
+
+	// 依赖尚未添加时等待依赖就绪
+	plugin := newServingModule("plugin", "base", "driver")
+	assert.Nil(t, mgr.AddModule("plugin", plugin))
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, StatusStopped, plugin.status.Get())
+
+	driver := newServingModule("driver")
+	assert.Nil(t, mgr.AddModule("driver", driver))
+	waitRunning(t, driver)
+	waitRunning(t, plugin)
+
+	assert.True(t, errors.Is(mgr.AddModule("driver", driver), ErrModuleExists))
+	assert.True(t, errors.Is(mgr.AddModule("loop", newServingModule("loop", "loop")), ErrSelfDependency))
+
+	<-mgr.Done(stop)
+}
+
+func TestManager_DelModule_running(t *testing.T) {
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+
+	mgr := NewManager()
+	db := newServingModule("db")
+	api := newServingModule("api", "db")
+	admin := newServingModule("admin", "api")
+	cache := newServingModule("cache")
+	for _, mod := range []*fakeModule{db, api, admin, cache} {
+		assert.Nil(t, mgr.AddModule(mod.name, mod))
+	}
+	go mgr.StartAndServe(ctx)
+	assert.Eventually(t, mgr.started.Load, time.Second, 5*time.Millisecond)
+
+	assert.True(t, errors.Is(mgr.DelModule("db"), ErrModuleRequired))
+	assert.True(t, errors.Is(mgr.DelModule("none"), ErrModuleNotExist))
+	assert.Equal(t, StatusRunning, db.status.Get())
+
+	assert.Nil(t, mgr.DelModule("cache"))
+	assert.Equal(t, StatusStopped, cache.status.Get())
+
+	removed, err := mgr.DelModuleCascade("db")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"admin", "api", "db"}, removed)
+	assert.Equal(t, StatusStopped, api.status.Get())
+	assert.Empty(t, mgr.moduleMap)
+	assert.Empty(t, mgr.cancelMap)
+
+	<-mgr.Done(stop)
+}
+
+// valueModule 以值类型实现 Module 且包含切片字段，无法使用 == 比较
+type valueModule struct {
+	*fakeModule
+	tags []string
+}
+
+func TestManager_DelModule_unlocked(t *testing.T) {
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+
+	mgr := NewManager()
+	mgr.Options.StopTimeout = time.Second
+	release := make(chan struct{})
+	slow := newServingModule("slow")
+	slow.run = func(ctx context.Context) {
+		slow.status.Set(StatusRunning)
+		<-ctx.Done()
+		<-release
+		slow.status.Set(StatusStopped)
+	}
+	assert.Nil(t, mgr.AddModule("slow", slow))
+	go mgr.StartAndServe(ctx)
+	waitRunning(t, slow)
+	assert.Eventually(t, mgr.started.Load, time.Second, 5*time.Millisecond)
+
+	deleted := make(chan error, 1)
+	go func() {
+		deleted <- mgr.DelModule("slow")
+	}()
+	assert.Eventually(t, func() bool {
+		return slow.status.Get() == StatusRunning && len(mgr.Graph().Nodes) == 0
+	}, time.Second, 5*time.Millisecond)
+
+	// 等待模块停止期间不持有模块映射的锁，可以继续添加模块
+	value := valueModule{fakeModule: newServingModule("value"), tags: []string{"a"}}
+	assert.Nil(t, mgr.AddModule("value", value))
+	waitRunning(t, value.fakeModule)
+
+	close(release)
+	assert.Nil(t, <-deleted)
+	assert.Equal(t, StatusStopped, slow.status.Get())
+
+	<-mgr.Done(stop)
+}
+
+func TestManager_AddModule_duringStartup(t *testing.T) {
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+
+	mgr := NewManager()
+	release := make(chan struct{})
+	slow := &fakeModule{name: "slow", status: NewModuleStatus()}
+	slow.run = func(ctx context.Context) {
+		<-release
+		slow.status.Set(StatusRunning)
+		<-ctx.Done()
+		slow.status.Set(StatusStopped)
+	}
+	assert.Nil(t, mgr.AddModule("slow", slow))
+	mgr.ctx = ctx
+	started := make(chan struct{})
+	go func() {
+		defer close(started)
+		mgr.startAll()
+	}()
+
+	// 启动期间添加的模块不在启动批次中，在管理器完成启动后按依赖启动
+	time.Sleep(20 * time.Millisecond)
+	late := newServingModule("late", "slow")
+	assert.Nil(t, mgr.AddModule("late", late))
+	close(release)
+	<-started
+	waitRunning(t, late)
+	<-mgr.Done(stop)
+}
+
+func TestManager_DelModule_optional(t *testing.T) {
+	mgr := NewManager()
+	assert.Nil(t, mgr.AddModule("tracing", &softModule{fakeModule: fakeModule{name: "tracing"}, groups: []string{"observe"}}))
+	assert.Nil(t, mgr.AddModule("metrics", &softModule{fakeModule: fakeModule{name: "metrics"}, optional: []string{"tracing"}}))
+	assert.Nil(t, mgr.AddModule("api", &fakeModule{name: "api", requires: []string{"@observe"}}))
+
+	// 分组依赖阻止删除并被级联删除，仅可选依赖该模块的模块被保留
+	assert.ErrorIs(t, mgr.DelModule("tracing"), ErrModuleRequired)
+	removed, err := mgr.DelModuleCascade("tracing")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"api", "tracing"}, removed)
+	_, ok := mgr.moduleMap["metrics"]
+	assert.True(t, ok)
+
+	assert.Nil(t, mgr.AddModule("tracing", &fakeModule{name: "tracing"}))
+	assert.Nil(t, mgr.DelModule("tracing"))
+}
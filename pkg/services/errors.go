@@ -0,0 +1,24 @@
+package services
+
+import "github.com/wolfbolin/bolbox/pkg/errors"
+
+var (
+	ErrMissingDependency   = errors.New("Module dependency is missing.")
+	ErrSelfDependency      = errors.New("Module depends on itself.")
+	ErrCyclicDependency    = errors.New("Module dependencies are cyclic.")
+	ErrModuleExists        = errors.New("Module already exists.")
+	ErrModuleNotExist      = errors.New("Module is not exist.")
+	ErrModuleRequired      = errors.New("Module is required by other modules.")
+	ErrModuleDisabled      = errors.New("Module is disabled.")
+	ErrUnknownRole         = errors.New("Role has no module.")
+	ErrStartTimeout        = errors.New("Module startup timeout.")
+	ErrStartFailed         = errors.New("Module stopped before running.")
+	ErrStopTimeout         = errors.New("Module stop timeout.")
+	ErrNotManaged          = errors.New("Context is not created by module manager.")
+	ErrNotProvided         = errors.New("Module value is not provided.")
+	ErrValueType           = errors.New("Module value type mismatch.")
+	ErrPoolClosed          = errors.New("Worker pool is closed.")
+	ErrQueueFull           = errors.New("Worker pool queue is full.")
+	ErrTaskPanic           = errors.New("Worker pool task panicked.")
+	ErrElectionUnsupported = errors.New("Leader election is not supported on this platform.")
+)
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+	"github.com/wolfbolin/bolbox/pkg/log"
+)
+
+// EventType 模块生命周期事件类型
+type EventType string
+
+const (
+	// EventStarting 模块即将启动
+	EventStarting EventType = "starting"
+	// EventStarted 模块已完成启动并切换状态
+	EventStarted EventType = "started"
+	// EventStopping 模块即将被通知退出
+	EventStopping EventType = "stopping"
+	// EventStopped 模块已退出运行
+	EventStopped EventType = "stopped"
+	// EventFailed 模块启动失败、未经通知退出或运行中抛出 panic
+	EventFailed EventType = "failed"
+)
+
+// Event 模块生命周期事件
+type Event struct {
+	Type   EventType
+	Module string
+	Time   time.Time
+	Err    error
+}
+
+// HookFunc 模块生命周期钩子函数。
+// BeforeStart 钩子返回错误时模块不会被启动，其余钩子返回的错误仅被记录。
+// 钩子在模块启动与停止的流程中同步执行，不可调用会获取模块映射锁的管理器方法。
+type HookFunc func(event Event) error
+
+type eventBus struct {
+	lock   sync.RWMutex
+	hooks  map[EventType][]HookFunc
+	subs   map[int]chan Event
+	nextID int
+}
+
+// BeforeStart 注册在模块启动前执行的钩子函数
+func (m *Manager) BeforeStart(hook HookFunc) {
+	m.events.addHook(EventStarting, hook)
+}
+
+// AfterStart 注册在模块完成启动后执行的钩子函数
+func (m *Manager) AfterStart(hook HookFunc) {
+	m.events.addHook(EventStarted, hook)
+}
+
+// BeforeStop 注册在模块被通知退出前执行的钩子函数
+func (m *Manager) BeforeStop(hook HookFunc) {
+	m.events.addHook(EventStopping, hook)
+}
+
+// AfterStop 注册在模块退出运行后执行的钩子函数
+func (m *Manager) AfterStop(hook HookFunc) {
+	m.events.addHook(EventStopped, hook)
+}
+
+// OnFailure 注册在模块启动失败、未经通知退出或抛出 panic 时执行的钩子函数
+func (m *Manager) OnFailure(hook HookFunc) {
+	m.events.addHook(EventFailed, hook)
+}
+
+// Subscribe 订阅模块生命周期事件，size 为事件通道的缓冲大小，通道已满时新的事件将被丢弃。
+// 返回的取消函数用于取消订阅并关闭事件通道。
+func (m *Manager) Subscribe(size int) (<-chan Event, func()) {
+	m.events.lock.Lock()
+	defer m.events.lock.Unlock()
+	if m.events.subs == nil {
+		m.events.subs = make(map[int]chan Event)
+	}
+
+	id, events := m.events.nextID, make(chan Event, max(size, 0))
+	m.events.subs[id] = events
+	m.events.nextID += 1
+
+	once := sync.Once{}
+	return events, func() {
+		once.Do(func() {
+			m.events.lock.Lock()
+			defer m.events.lock.Unlock()
+			delete(m.events.subs, id)
+			close(events)
+		})
+	}
+}
+
+// LogEvents 将事件通道中的模块生命周期事件输出到日志，直至通道被关闭
+func LogEvents(events <-chan Event) {
+	for event := range events {
+		if event.Err != nil {
+			log.Errorw("Module lifecycle event", "module", event.Module, "event", event.Type,
+				"time", event.Time, "error", event.Err.Error())
+			continue
+		}
+		log.Infow("Module lifecycle event", "module", event.Module, "event", event.Type, "time", event.Time)
+	}
+}
+
+// emit 记录模块的运行统计并发送生命周期事件，返回钩子函数的错误
+func (m *Manager) emit(event Event) error {
+	if event.Time.IsZero() {
+		event.Time = m.clock().Now()
+	}
+	m.stats.record(event)
+	return m.events.emit(event)
+}
+
+func (b *eventBus) addHook(eventType EventType, hook HookFunc) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.hooks == nil {
+		b.hooks = make(map[EventType][]HookFunc)
+	}
+	b.hooks[eventType] = append(b.hooks[eventType], hook)
+}
+
+// emit 依次执行事件对应的钩子函数并向全部订阅者广播事件，返回钩子函数的错误
+func (b *eventBus) emit(event Event) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	b.lock.RLock()
+	hooks := b.hooks[event.Type]
+	b.lock.RUnlock()
+
+	errs := make([]error, 0)
+	for _, hook := range hooks {
+		if err := hook(event); err != nil {
+			log.Warnf("Module[%s] hook for event[%s] failed. %+v", event.Module, event.Type, err)
+			errs = append(errs, err)
+		}
+	}
+
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	for _, sub := range b.subs {
+		select {
+		case sub <- event:
+		default:
+		}
+	}
+	return errors.Join(errs...)
+}
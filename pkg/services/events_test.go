@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+)
+
+func TestManager_hooks(t *testing.T) {
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+
+	lock := sync.Mutex{}
+	records := make([]string, 0)
+	record := func(event Event) error {
+		lock.Lock()
+		defer lock.Unlock()
+		records = append(records, string(event.Type)+":"+event.Module)
+		return nil
+	}
+
+	mgr := NewManager()
+	mgr.BeforeStart(record)
+	mgr.AfterStart(record)
+	mgr.BeforeStop(record)
+	mgr.AfterStop(record)
+	mgr.OnFailure(record)
+	mgr.BeforeStart(func(event Event) error {
+		if event.Module == "broken" {
+			return errors.New("warm up failed")
+		}
+		return nil
+	})
+	assert.Nil(t, mgr.AddModule("db", newServingModule("db")))
+	mgr.ctx = ctx
+	mgr.startAll()
+
+	assert.Nil(t, mgr.AddModule("broken", newServingModule("broken")))
+	assert.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(records) == 4
+	}, time.Second, 5*time.Millisecond)
+	assert.Nil(t, mgr.DelModule("broken"))
+
+	assert.Nil(t, mgr.DelModule("db"))
+	assert.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(records) == 6
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{
+		"starting:db", "started:db",
+		"starting:broken", "failed:broken",
+		"stopping:db", "stopped:db",
+	}, records)
+}
+
+func TestManager_Subscribe(t *testing.T) {
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+
+	mgr := NewManager()
+	events, cancel := mgr.Subscribe(16)
+	assert.Nil(t, mgr.AddModule("crash", &fakeModule{
+		name:   "crash",
+		status: NewModuleStatus(),
+		run: func(ctx context.Context) {
+			time.Sleep(10 * time.Millisecond)
+		},
+	}))
+	mgr.ctx = ctx
+	mgr.Options.StartTimeout = 50 * time.Millisecond
+	mgr.Options.FailFast = false
+	go mgr.startAll()
+
+	event := <-events
+	assert.Equal(t, EventStarting, event.Type)
+	assert.Equal(t, "crash", event.Module)
+	assert.False(t, event.Time.IsZero())
+
+	event = <-events
+	assert.Equal(t, EventFailed, event.Type)
+	assert.NotNil(t, event.Err)
+
+	cancel()
+	cancel()
+	_, ok := <-events
+	for ok {
+		_, ok = <-events
+	}
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Graph 模块依赖关系图，边由依赖方指向被依赖方
+type Graph struct {
+	Nodes []string            // 全部模块名称（有序）
+	Edges map[string][]string // 模块名称到其依赖列表（有序）
+}
+
+// Graph 导出当前管理器中模块的依赖关系图，分组依赖被展开为具体模块。
+// 生效的可选依赖与启动顺序提示同样作为边输出，未添加的依赖同样作为节点输出
+func (m *Manager) Graph() *Graph {
+	m.mapLock.RLock()
+	defer m.mapLock.RUnlock()
+
+	nodeSet := make(map[string]struct{})
+	graph := &Graph{
+		Nodes: make([]string, 0, len(m.moduleMap)),
+		Edges: make(map[string][]string),
+	}
+	set := m.modules()
+	for name := range m.moduleMap {
+		nodeSet[name] = struct{}{}
+		requires := set.startAfter(name)
+		sort.Strings(requires)
+		if len(requires) != 0 {
+			graph.Edges[name] = requires
+		}
+		for _, depMod := range requires {
+			nodeSet[depMod] = struct{}{}
+		}
+	}
+	for name := range nodeSet {
+		graph.Nodes = append(graph.Nodes, name)
+	}
+	sort.Strings(graph.Nodes)
+	return graph
+}
+
+// DOT 以 Graphviz DOT 格式输出依赖关系图
+func (g *Graph) DOT() string {
+	builder := strings.Builder{}
+	builder.WriteString("digraph modules {\n")
+	for _, name := range g.Nodes {
+		builder.WriteString(fmt.Sprintf("  %q;\n", name))
+	}
+	for _, name := range g.Nodes {
+		for _, depMod := range g.Edges[name] {
+			builder.WriteString(fmt.Sprintf("  %q -> %q;\n", name, depMod))
+		}
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+// Mermaid 以 Mermaid flowchart 格式输出依赖关系图
+func (g *Graph) Mermaid() string {
+	nodeID := make(map[string]string, len(g.Nodes))
+	builder := strings.Builder{}
+	builder.WriteString("flowchart LR\n")
+	for i, name := range g.Nodes {
+		nodeID[name] = fmt.Sprintf("m%d", i)
+		builder.WriteString(fmt.Sprintf("  %s[%q]\n", nodeID[name], name))
+	}
+	for _, name := range g.Nodes {
+		for _, depMod := range g.Edges[name] {
+			builder.WriteString(fmt.Sprintf("  %s --> %s\n", nodeID[name], nodeID[depMod]))
+		}
+	}
+	return builder.String()
+}
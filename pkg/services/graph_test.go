@@ -0,0 +1,24 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager_Graph(t *testing.T) {
+	mgr := NewManager()
+	mgr.AddModule("api", &fakeModule{name: "api", requires: []string{"db", "cache"}})
+	mgr.AddModule("db", &fakeModule{name: "db"})
+
+	graph := mgr.Graph()
+	assert.Equal(t, []string{"api", "cache", "db"}, graph.Nodes)
+	assert.Equal(t, []string{"cache", "db"}, graph.Edges["api"])
+
+	assert.Equal(t, "digraph modules {\n"+
+		"  \"api\";\n  \"cache\";\n  \"db\";\n"+
+		"  \"api\" -> \"cache\";\n  \"api\" -> \"db\";\n}\n", graph.DOT())
+	assert.Equal(t, "flowchart LR\n"+
+		"  m0[\"api\"]\n  m1[\"cache\"]\n  m2[\"db\"]\n"+
+		"  m0 --> m1\n  m0 --> m2\n", graph.Mermaid())
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+	"github.com/wolfbolin/bolbox/pkg/mix"
+)
+
+// DefaultHealthTimeout 健康检查未指定超时时间时使用的默认值
+const DefaultHealthTimeout = time.Second
+
+// HealthCheckFunc 模块健康检查函数，返回 nil 表示模块健康
+type HealthCheckFunc func(ctx context.Context) error
+
+// HealthChecker 可选接口，模块通过其提供默认的健康检查。
+// 未通过 SetHealthCheck 设置健康检查的模块在 Health 中使用该检查，检查失败时模块仅被视为未就绪，不影响存活
+type HealthChecker interface {
+	Check(ctx context.Context) error
+}
+
+// HealthCheck 模块健康检查配置，支持超时控制与结果缓存
+type HealthCheck struct {
+	Check    HealthCheckFunc
+	Timeout  time.Duration // 单次检查的超时时间，不大于 0 时使用 DefaultHealthTimeout
+	CacheTTL time.Duration // 检查结果的缓存时间，不大于 0 时表示不缓存
+
+	ReadinessOnly bool // 检查失败时仅使管理器未就绪，不影响存活，适用于依赖外部服务等重启无法恢复的检查
+
+	lock    sync.Mutex
+	checkAt time.Time
+	lastErr error
+}
+
+// run 使用管理器的时钟执行健康检查，缓存有效期内直接返回上一次的检查结果
+func (h *HealthCheck) run(ctx context.Context, clock Clock) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.CacheTTL > 0 && !h.checkAt.IsZero() && clock.Now().Sub(h.checkAt) < h.CacheTTL {
+		return h.lastErr
+	}
+
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHealthTimeout
+	}
+	checkCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errChan := make(chan error, 1)
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				errChan <- errors.Errorf("Health check throws a panic. %v", err)
+			}
+		}()
+		errChan <- h.Check(checkCtx)
+	}()
+
+	var err error
+	select {
+	case err = <-errChan:
+	case <-clock.After(timeout):
+		err = errors.Wrapf(context.DeadlineExceeded, "Health check timeout after %s", timeout)
+	case <-checkCtx.Done():
+		err = errors.Wrapf(checkCtx.Err(), "Health check is canceled")
+	}
+	h.checkAt, h.lastErr = clock.Now(), err
+	return err
+}
+
+// ModuleReport 单个模块的健康状态
+type ModuleReport struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// HealthReport 管理器中全部模块的健康状态汇总
+type HealthReport struct {
+	Live    bool           `json:"live"`
+	Ready   bool           `json:"ready"`
+	Modules []ModuleReport `json:"modules"`
+}
+
+// SetHealthCheck 为指定模块设置健康检查，传入 nil 时移除该模块的健康检查
+func (m *Manager) SetHealthCheck(name string, check *HealthCheck) {
+	m.mapLock.Lock()
+	defer m.mapLock.Unlock()
+	if check == nil || check.Check == nil {
+		delete(m.healthMap, name)
+		return
+	}
+	m.healthMap[name] = check
+}
+
+// Health 汇总全部启用模块的状态并执行健康检查，模块列表在持有读锁时获取快照，检查期间不持有锁。
+// 运行中的模块中 ReadinessOnly 以外的健康检查全部通过时视为存活；管理器完成启动且全部模块运行中并健康时视为就绪。
+func (m *Manager) Health(ctx context.Context) *HealthReport {
+	m.mapLock.RLock()
+	modules := make([]Module, 0, len(m.moduleMap))
+	checks := make(map[string]*HealthCheck, len(m.healthMap))
+	set := m.modules()
+	for name, module := range m.moduleMap {
+		if !set.enabled(name) {
+			continue
+		}
+		modules = append(modules, module)
+		if check, ok := m.healthMap[name]; ok {
+			checks[name] = check
+		} else if checker, ok := module.(HealthChecker); ok {
+			checks[name] = &HealthCheck{Check: checker.Check, ReadinessOnly: true}
+		}
+	}
+	m.mapLock.RUnlock()
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].Name() < modules[j].Name()
+	})
+
+	report := &HealthReport{
+		Live:    true,
+		Ready:   m.started.Load(),
+		Modules: make([]ModuleReport, len(modules)),
+	}
+	clock := m.clock()
+	wg := sync.WaitGroup{}
+	for i, module := range modules {
+		modReport := &report.Modules[i]
+		modReport.Name = module.Name()
+		modReport.Healthy = true
+		if modStatus := module.Status(); modStatus != nil {
+			modReport.Status = modStatus.Get()
+		}
+
+		check, ok := checks[module.Name()]
+		if !ok || modReport.Status != StatusRunning {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := check.run(ctx, clock); err != nil {
+				modReport.Healthy = false
+				modReport.Error = err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, modReport := range report.Modules {
+		if !modReport.Healthy {
+			report.Live = report.Live && checks[modReport.Name].ReadinessOnly
+			report.Ready = false
+		}
+		if modReport.Status != StatusRunning {
+			report.Ready = false
+		}
+	}
+	return report
+}
+
+// Handler 返回提供 /healthz、/readyz 与 /status 接口的 http.Handler。
+// /healthz 与 /readyz 在检查失败时返回 503，/status 始终返回 200 并附带全部模块的详细状态。
+func (m *Manager) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		report := m.Health(r.Context())
+		writeReport(w, report, report.Live)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		report := m.Health(r.Context())
+		writeReport(w, report, report.Ready)
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, m.Health(r.Context()), true)
+	})
+	return mux
+}
+
+func writeReport(w http.ResponseWriter, report *HealthReport, ok bool) {
+	code := http.StatusOK
+	if !ok {
+		code = http.StatusServiceUnavailable
+	}
+	mix.HttpRsp(w).Header("Content-Type", "application/json").Code(code).Json(report)
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+)
+
+func newRunningModule(name string) *fakeModule {
+	mod := &fakeModule{
+		name:   name,
+		status: NewModuleStatus(),
+	}
+	mod.status.Set(StatusRunning)
+	return mod
+}
+
+// manualClock 仅在测试中手动推进当前时间的时钟
+type manualClock struct {
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	return c.now
+}
+
+func (c *manualClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func TestHealthCheck_run(t *testing.T) {
+	clock := &manualClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	calls := 0
+	check := &HealthCheck{
+		Check: func(ctx context.Context) error {
+			calls += 1
+			return errors.New("unhealthy")
+		},
+		CacheTTL: time.Minute,
+	}
+	assert.NotNil(t, check.run(context.TODO(), clock))
+	assert.NotNil(t, check.run(context.TODO(), clock))
+	assert.Equal(t, 1, calls)
+
+	// 缓存按管理器的时钟过期
+	clock.now = clock.now.Add(time.Minute)
+	assert.NotNil(t, check.run(context.TODO(), clock))
+	assert.Equal(t, 2, calls)
+
+	check = &HealthCheck{
+		Check: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Timeout: 10 * time.Millisecond,
+	}
+	assert.ErrorIs(t, check.run(context.TODO(), clock), context.DeadlineExceeded)
+}
+
+func TestManager_Handler(t *testing.T) {
+	mgr := NewManager()
+	mgr.AddModule("A", newRunningModule("A"))
+	mgr.AddModule("B", newRunningModule("B"))
+
+	serve := func(path string) (int, *HealthReport) {
+		rec := httptest.NewRecorder()
+		mgr.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		report := &HealthReport{}
+		assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), report))
+		return rec.Code, report
+	}
+
+	// 管理器尚未完成启动
+	code, _ := serve("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	code, _ = serve("/healthz")
+	assert.Equal(t, http.StatusOK, code)
+
+	mgr.started.Store(true)
+	code, _ = serve("/readyz")
+	assert.Equal(t, http.StatusOK, code)
+
+	mgr.SetHealthCheck("B", &HealthCheck{
+		Check: func(ctx context.Context) error {
+			return errors.New("connection refused")
+		},
+	})
+	code, report := serve("/healthz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "B", report.Modules[1].Name)
+	assert.False(t, report.Modules[1].Healthy)
+	assert.Equal(t, "connection refused", report.Modules[1].Error)
+
+	code, report = serve("/status")
+	assert.Equal(t, http.StatusOK, code)
+	assert.False(t, report.Ready)
+	assert.Len(t, report.Modules, 2)
+
+	// ReadinessOnly 的检查失败时仅影响就绪
+	mgr.SetHealthCheck("B", &HealthCheck{
+		Check: func(ctx context.Context) error {
+			return errors.New("upstream unavailable")
+		},
+		ReadinessOnly: true,
+	})
+	code, _ = serve("/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	code, report = serve("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.False(t, report.Modules[1].Healthy)
+}
+
+func TestManager_Health_duringStartup(t *testing.T) {
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+
+	mgr := NewManager()
+	mgr.Options.StartTimeout = 5 * time.Second
+	release := make(chan struct{})
+	slow := &fakeModule{name: "slow", status: NewModuleStatus()}
+	slow.run = func(ctx context.Context) {
+		<-release
+		slow.status.Set(StatusRunning)
+		<-ctx.Done()
+		slow.status.Set(StatusStopped)
+	}
+	assert.Nil(t, mgr.AddModule("slow", slow))
+	mgr.ctx = ctx
+	started := make(chan struct{})
+	go func() {
+		defer close(started)
+		mgr.startAll()
+	}()
+
+	// 模块启动期间健康检查立即返回未就绪，而不是等待启动结束
+	reported := make(chan *HealthReport, 1)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		reported <- mgr.Health(context.TODO())
+	}()
+	select {
+	case report := <-reported:
+		assert.True(t, report.Live)
+		assert.False(t, report.Ready)
+		assert.Equal(t, StatusStopped, report.Modules[0].Status)
+	case <-time.After(time.Second):
+		t.Fatal("Health is blocked by module startup")
+	}
+
+	close(release)
+	<-started
+	assert.True(t, mgr.Health(context.TODO()).Ready)
+	<-mgr.Done(stop)
+}
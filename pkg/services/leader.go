@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+	"github.com/wolfbolin/bolbox/pkg/log"
+)
+
+// Elector 领导者选举接口
+type Elector interface {
+	// Campaign 阻塞直至成为领导者或 ctx 结束，成为领导者后返回一个在失去领导权时关闭的通道
+	Campaign(ctx context.Context) (<-chan struct{}, error)
+	// Resign 主动放弃领导权，未持有领导权时不做任何操作
+	Resign(ctx context.Context) error
+}
+
+// DefaultCampaignRetry 竞选失败后重新竞选的默认等待时间
+const DefaultCampaignRetry = time.Second
+
+// LeaderModule 仅在持有领导权时运行被包装模块的模块，用于只能在一个副本上运行的单例模块。
+// 模块启动后立即切换为运行状态并参与竞选，成为领导者后运行被包装模块，失去领导权时结束其上下文并重新竞选。
+// 模块的名称、依赖、分组、角色与启动顺序提示均与被包装模块相同。
+type LeaderModule struct {
+	*FuncModule
+	inner   Module
+	elector Elector
+	leader  atomic.Bool
+
+	RetryInterval time.Duration // 竞选失败后重新竞选的等待时间
+}
+
+var (
+	_ OptionalRequirer = (*LeaderModule)(nil)
+	_ AfterHinter      = (*LeaderModule)(nil)
+	_ Grouper          = (*LeaderModule)(nil)
+	_ Roler            = (*LeaderModule)(nil)
+)
+
+// NewLeaderModule 创建一个仅在 elector 选举为领导者时运行 module 的模块
+func NewLeaderModule(module Module, elector Elector) *LeaderModule {
+	l := &LeaderModule{
+		inner:         module,
+		elector:       elector,
+		RetryInterval: DefaultCampaignRetry,
+	}
+	l.FuncModule = newFuncModule(module.Name(), module.Requires(), func(ctx context.Context, ready func()) error {
+		ready()
+		return l.serve(ctx)
+	})
+	return l
+}
+
+// IsLeader 返回模块当前是否持有领导权
+func (l *LeaderModule) IsLeader() bool {
+	return l.leader.Load()
+}
+
+// OptionalRequires 返回被包装模块的可选依赖
+func (l *LeaderModule) OptionalRequires() []string {
+	if optional, ok := l.inner.(OptionalRequirer); ok {
+		return optional.OptionalRequires()
+	}
+	return nil
+}
+
+// After 返回被包装模块的启动顺序提示
+func (l *LeaderModule) After() []string {
+	if hinter, ok := l.inner.(AfterHinter); ok {
+		return hinter.After()
+	}
+	return nil
+}
+
+// Groups 返回被包装模块所属的分组
+func (l *LeaderModule) Groups() []string {
+	if grouper, ok := l.inner.(Grouper); ok {
+		return grouper.Groups()
+	}
+	return nil
+}
+
+// Roles 返回被包装模块所属的运行角色
+func (l *LeaderModule) Roles() []string {
+	if roler, ok := l.inner.(Roler); ok {
+		return roler.Roles()
+	}
+	return nil
+}
+
+// serve 循环竞选领导权并在持有期间运行被包装模块，直至 ctx 结束或被包装模块自行退出
+func (l *LeaderModule) serve(ctx context.Context) error {
+	name, runs := l.inner.Name(), 0
+	for {
+		lost, err := l.elector.Campaign(ctx)
+		if ctx.Err() != nil {
+			if err == nil {
+				l.resign(name)
+			}
+			return nil
+		}
+		if err != nil {
+			log.Errorf("Module[%s] campaign for leadership failed. %+v", name, err)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(l.RetryInterval):
+			}
+			continue
+		}
+
+		log.Infof("Module[%s] acquired leadership", name)
+		if runs += 1; runs > 1 {
+			recordRestart(ctx)
+		}
+		l.leader.Store(true)
+		exited, runErr := l.runInner(ctx, lost)
+		l.leader.Store(false)
+
+		switch {
+		case ctx.Err() != nil:
+			l.resign(name)
+			return nil
+		case exited:
+			l.resign(name)
+			return errors.Wrapf(runErr, "Module[%s] exited while holding leadership", name)
+		default:
+			// 选举者可能仍认为自己持有领导权，释放后再重新竞选
+			l.resign(name)
+			log.Warnf("Module[%s] lost leadership, campaign again", name)
+		}
+	}
+}
+
+// runInner 运行被包装模块直至其退出、ctx 结束或失去领导权，返回被包装模块是否自行退出及其报告的错误
+func (l *LeaderModule) runInner(ctx context.Context, lost <-chan struct{}) (bool, error) {
+	innerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+	panicErr := error(nil)
+	go func() {
+		defer close(done)
+		defer func() {
+			if err := recover(); err != nil {
+				panicErr = errors.Errorf("Module[%s] throws a panic during running. %+v", l.inner.Name(), err)
+			}
+		}()
+		l.inner.Run(innerCtx)
+	}()
+
+	exited := false
+	select {
+	case <-done:
+		exited = true
+	case <-lost:
+	case <-ctx.Done():
+	}
+	cancel()
+	<-done
+
+	if panicErr != nil {
+		return exited, panicErr
+	}
+	if reporter, ok := l.inner.(ErrorReporter); ok {
+		return exited, reporter.Err()
+	}
+	return exited, nil
+}
+
+func (l *LeaderModule) resign(name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), l.RetryInterval)
+	defer cancel()
+	if err := l.elector.Resign(ctx); err != nil {
+		log.Errorf("Module[%s] resign leadership failed. %+v", name, err)
+	}
+}
+
+// MemoryElection 进程内的领导者选举，同一选举中的候选者互斥地持有领导权，用于测试
+type MemoryElection struct {
+	lock    sync.Mutex
+	leader  string
+	lost    chan struct{}
+	changed chan struct{}
+}
+
+// NewMemoryElection 创建一个进程内的领导者选举
+func NewMemoryElection() *MemoryElection {
+	return &MemoryElection{
+		changed: make(chan struct{}),
+	}
+}
+
+// Elector 返回以 id 参与选举的候选者
+func (e *MemoryElection) Elector(id string) Elector {
+	return &memoryElector{election: e, id: id}
+}
+
+// Leader 返回当前领导者的标识，没有领导者时返回空字符串
+func (e *MemoryElection) Leader() string {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.leader
+}
+
+// Revoke 撤销当前领导者的领导权，用于模拟失去领导权
+func (e *MemoryElection) Revoke() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.release(e.leader)
+}
+
+// release 释放 id 持有的领导权，调用方需持有锁
+func (e *MemoryElection) release(id string) {
+	if e.leader == "" || e.leader != id {
+		return
+	}
+	e.leader = ""
+	close(e.lost)
+	close(e.changed)
+	e.changed = make(chan struct{})
+}
+
+type memoryElector struct {
+	election *MemoryElection
+	id       string
+}
+
+func (m *memoryElector) Campaign(ctx context.Context) (<-chan struct{}, error) {
+	e := m.election
+	for {
+		e.lock.Lock()
+		if e.leader == "" {
+			e.leader, e.lost = m.id, make(chan struct{})
+		}
+		if e.leader == m.id {
+			lost := e.lost
+			e.lock.Unlock()
+			return lost, nil
+		}
+		changed := e.changed
+		e.lock.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.WithStack(ctx.Err())
+		case <-changed:
+		}
+	}
+}
+
+func (m *memoryElector) Resign(ctx context.Context) error {
+	m.election.lock.Lock()
+	defer m.election.lock.Unlock()
+	m.election.release(m.id)
+	return nil
+}
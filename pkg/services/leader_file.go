@@ -0,0 +1,146 @@
+//go:build unix
+
+package services
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+)
+
+// FileElector 基于文件锁的领导者选举，适用于同一主机上的多个进程。
+// 持有锁的文件被删除或替换时视为失去领导权。
+type FileElector struct {
+	path     string
+	interval time.Duration
+
+	lock sync.Mutex
+	file *os.File
+	lost chan struct{}
+	stop chan struct{}
+}
+
+// NewFileElector 创建一个竞争 path 文件锁的选举者，interval 为重试加锁与检查锁文件的间隔
+func NewFileElector(path string, interval time.Duration) *FileElector {
+	if interval <= 0 {
+		interval = DefaultCampaignRetry
+	}
+	return &FileElector{
+		path:     path,
+		interval: interval,
+	}
+}
+
+// Campaign 阻塞直至获得文件锁或 ctx 结束，获得文件锁后将当前进程号写入锁文件
+func (f *FileElector) Campaign(ctx context.Context) (<-chan struct{}, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.file != nil {
+		return f.lost, nil
+	}
+
+	for {
+		file, err := f.tryLock()
+		if err != nil {
+			return nil, err
+		}
+		if file != nil {
+			f.file, f.lost, f.stop = file, make(chan struct{}), make(chan struct{})
+			go f.watch(file, f.lost, f.stop)
+			return f.lost, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.WithStack(ctx.Err())
+		case <-time.After(f.interval):
+		}
+	}
+}
+
+// Resign 释放文件锁
+func (f *FileElector) Resign(ctx context.Context) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	close(f.stop)
+	err := f.file.Close() // 关闭文件描述符同时释放文件锁
+	f.file = nil
+	if err != nil {
+		return errors.Wrapf(err, "Release file lock [%s] failed", f.path)
+	}
+	return nil
+}
+
+// tryLock 尝试以非阻塞方式获取文件锁，锁被其他进程持有时返回 nil
+func (f *FileElector) tryLock() (*os.File, error) {
+	file, err := os.OpenFile(f.path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Open lock file [%s] failed", f.path)
+	}
+	if err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "Lock file [%s] failed", f.path)
+	}
+	if !f.sameFile(file) {
+		_ = file.Close() // 加锁期间锁文件被删除或替换
+		return nil, nil
+	}
+
+	_ = file.Truncate(0)
+	_, _ = file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	return file, nil
+}
+
+// watch 定期检查锁文件，文件被删除或替换时释放文件锁并关闭 lost 通道，之后的 Campaign 重新竞争新的锁文件
+func (f *FileElector) watch(file *os.File, lost, stop chan struct{}) {
+	defer close(lost)
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if !f.sameFile(file) {
+				f.release(file)
+				return
+			}
+		}
+	}
+}
+
+// release 在 file 仍是当前持有的锁文件时关闭它，Resign 已释放时不做任何操作
+func (f *FileElector) release(file *os.File) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.file != file {
+		return
+	}
+	close(f.stop)
+	_ = file.Close()
+	f.file = nil
+}
+
+// sameFile 检查已打开的文件是否仍是 path 指向的文件
+func (f *FileElector) sameFile(file *os.File) bool {
+	opened, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	current, err := os.Stat(f.path)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(opened, current)
+}
@@ -0,0 +1,26 @@
+//go:build !unix
+
+package services
+
+import (
+	"context"
+	"time"
+)
+
+// FileElector 基于文件锁的领导者选举，当前平台不支持
+type FileElector struct{}
+
+// NewFileElector 创建一个竞争 path 文件锁的选举者，当前平台不支持，竞选总是返回 ErrElectionUnsupported
+func NewFileElector(path string, interval time.Duration) *FileElector {
+	return &FileElector{}
+}
+
+// Campaign 当前平台不支持文件锁选举
+func (f *FileElector) Campaign(ctx context.Context) (<-chan struct{}, error) {
+	return nil, ErrElectionUnsupported
+}
+
+// Resign 当前平台不支持文件锁选举
+func (f *FileElector) Resign(ctx context.Context) error {
+	return nil
+}
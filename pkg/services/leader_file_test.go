@@ -0,0 +1,71 @@
+//go:build unix
+
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileElector(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leader.lock")
+	first := NewFileElector(path, 10*time.Millisecond)
+	second := NewFileElector(path, 10*time.Millisecond)
+
+	lost, err := first.Campaign(context.TODO())
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+	_, err = second.Campaign(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.Nil(t, first.Resign(context.TODO()))
+	<-lost
+	lost, err = second.Campaign(context.TODO())
+	assert.Nil(t, err)
+
+	// 锁文件被删除时失去领导权
+	assert.Nil(t, os.Remove(path))
+	select {
+	case <-lost:
+	case <-time.After(time.Second):
+		t.Fatal("Leadership was not lost after the lock file was removed")
+	}
+	assert.Nil(t, second.Resign(context.TODO()))
+}
+
+func TestLeaderModuleFileLost(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leader.lock")
+	runs := atomic.Int32{}
+	mod := NewLeaderModule(newCountingModule("job", &runs), NewFileElector(path, 10*time.Millisecond))
+	mod.RetryInterval = 10 * time.Millisecond
+
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+	go mod.Run(ctx)
+	assert.Eventually(t, func() bool { return runs.Load() == 1 }, time.Second, time.Millisecond)
+
+	// 锁文件被删除后被包装模块停止，并竞争新创建的锁文件
+	assert.Nil(t, os.Remove(path))
+	assert.Eventually(t, func() bool { return runs.Load() == 2 }, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(2), runs.Load()) // 持有新的锁文件时不再重启
+	assert.True(t, mod.IsLeader())
+
+	// 新创建的锁文件被当前进程持有
+	other := NewFileElector(path, 10*time.Millisecond)
+	otherCtx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+	_, err := other.Campaign(otherCtx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	stop()
+	assert.Nil(t, waitStopped(context.TODO(), mod.Status()))
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newCountingModule(name string, runs *atomic.Int32) *FuncModule {
+	return NewFuncModule(name, nil, func(ctx context.Context) error {
+		runs.Add(1)
+		<-ctx.Done()
+		return nil
+	})
+}
+
+func TestLeaderModule(t *testing.T) {
+	election := NewMemoryElection()
+	runsA, runsB := atomic.Int32{}, atomic.Int32{}
+	modA := NewLeaderModule(newCountingModule("job", &runsA), election.Elector("a"))
+	modB := NewLeaderModule(newCountingModule("job", &runsB), election.Elector("b"))
+	modA.RetryInterval, modB.RetryInterval = 10*time.Millisecond, 10*time.Millisecond
+
+	ctxA, stopA := context.WithCancel(context.TODO())
+	defer stopA()
+	go modA.Run(ctxA)
+	assert.Eventually(t, modA.IsLeader, time.Second, time.Millisecond)
+	assert.Equal(t, "a", election.Leader())
+
+	// 备用副本同样处于运行状态，但不会运行被包装模块
+	ctxB, stopB := context.WithCancel(context.TODO())
+	defer stopB()
+	changed := modB.Status().Changed()
+	go modB.Run(ctxB)
+	<-changed
+	assert.Equal(t, StatusRunning, modB.Status().Get())
+	assert.False(t, modB.IsLeader())
+	assert.Equal(t, int32(0), runsB.Load())
+
+	// 领导者退出后备用副本接管
+	stopA()
+	assert.Nil(t, waitStopped(context.TODO(), modA.Status()))
+	assert.Eventually(t, modB.IsLeader, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool { return runsB.Load() == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, int32(1), runsA.Load())
+
+	// 失去领导权时被包装模块的上下文结束，重新竞选成功后再次运行
+	election.Revoke()
+	assert.Eventually(t, func() bool { return runsB.Load() == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, "b", election.Leader())
+
+	stopB()
+	assert.Nil(t, waitStopped(context.TODO(), modB.Status()))
+	assert.Nil(t, modB.Err())
+	assert.Equal(t, "", election.Leader())
+}
+
+func TestLeaderModuleInnerExit(t *testing.T) {
+	election := NewMemoryElection()
+	mod := NewLeaderModule(NewFuncModule("once", []string{"db"}, func(ctx context.Context) error {
+		return nil
+	}), election.Elector("a"))
+	assert.Equal(t, "once", mod.Name())
+	assert.Equal(t, []string{"db"}, mod.Requires())
+
+	mod.Run(context.TODO())
+	assert.Equal(t, StatusStopped, mod.Status().Get())
+	assert.Nil(t, mod.Err())
+	assert.Equal(t, "", election.Leader())
+}
@@ -2,7 +2,10 @@ package services
 
 import (
 	"context"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/wolfbolin/bolbox/pkg/errors"
@@ -20,150 +23,400 @@ type Module interface {
 // Manager 结构体管理模块的生命周期，包括上下文和取消功能。提供添加、删除和管理模块的方法。
 type Manager struct {
 	ctx        context.Context
-	role       string
 	mapLock    sync.RWMutex
 	moduleMap  map[string]Module
 	contextMap map[string]context.Context
 	cancelMap  map[string]context.CancelFunc
+	healthMap  map[string]*HealthCheck
+	mapChanged chan struct{}
+	genMap     map[string]uint64 // 运行期间添加的模块的添加序号，用于识别被删除后重新添加的同名模块
+	generation uint64
+	started    atomic.Bool
+	events     eventBus
+	values     registry
+	stats      statsRecorder
+
+	Options *Options
 }
 
 // NewManager 创建一个新的Manager实例，初始化模块、上下文和取消功能的映射。
 func NewManager() *Manager {
 	return &Manager{
-		role:       "",
 		moduleMap:  make(map[string]Module),
 		contextMap: make(map[string]context.Context),
 		cancelMap:  make(map[string]context.CancelFunc),
+		healthMap:  make(map[string]*HealthCheck),
+		Options:    DefaultOptions(),
 	}
 }
 
-// StartAndServe 初始化管理器的上下文，锁定模块映射，检查和排序模块启动顺序，并按顺序启动每个模块。处理启动错误并监控状态变化。
+// StartAndServe 初始化管理器的上下文，锁定模块映射，检查和排序模块启动顺序，并按依赖批次启动每个模块。处理启动错误并监控状态变化。
 func (m *Manager) StartAndServe(ctx context.Context) {
 	m.ctx = ctx
-	m.mapLock.Lock()
-	defer m.mapLock.Unlock()
+	m.startAll()
+
+	select {
+	case <-m.ctx.Done():
+		log.Infof("Module manager exit by context")
+		return
+	}
+}
 
-	order, err := m.checkAndSort()
+// startAll 按依赖批次启动全部模块，同一批次内的模块并发启动。
+// 模块的运行上下文在持有写锁时创建，等待模块切换状态期间释放锁，使健康检查等读取方不被启动过程阻塞。
+func (m *Manager) startAll() {
+	m.mapLock.Lock()
+	set := m.modules()
+	waves, err := m.checkAndWaveOf(set)
 	if err != nil {
+		m.mapLock.Unlock()
 		log.Fatalf("Check for module startup sequence errors. %+v", err)
+		return
 	}
-	log.Infof("Module manager will sequentially start the following modules: %v", order)
+	log.Infof("Module manager will start the following modules in waves: %v", waves)
+	if disabled := m.disabledModules(); len(disabled) != 0 {
+		log.Infof("Modules%v are disabled by groups%v or roles%v", disabled, m.options().DisabledGroups, m.options().Roles)
+	}
+	m.mapLock.Unlock()
 
-	for _, modName := range order {
+	options := m.options()
+	for i, wave := range waves {
+		log.Infof("Start function modules%v in wave[%d]", wave, i)
+		starts := make([]func() error, 0, len(wave))
+		m.mapLock.Lock()
+		for _, modName := range wave {
+			if _, ok := m.moduleMap[modName]; !ok {
+				log.Infof("Module[%s] has been deleted before started", modName)
+				continue
+			}
+			if start := m.prepareModule(set, modName, options.StartTimeout); start != nil {
+				starts = append(starts, start)
+			}
+		}
+		m.mapLock.Unlock()
+
+		limit := options.StartConcurrency
+		if limit <= 0 || limit > len(starts) {
+			limit = len(starts)
+		}
+		wg := sync.WaitGroup{}
+		sem := make(chan struct{}, max(limit, 1))
+		for _, start := range starts {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				if err := start(); err != nil {
+					m.startFailed(err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	m.mapLock.Lock()
+	defer m.mapLock.Unlock()
+	m.started.Store(true)
+	// 启动期间添加的模块不在启动批次中，与运行期间添加的模块一样在依赖就绪后启动
+	set = m.modules()
+	for _, name := range set.enabledModules() {
+		if _, ok := m.contextMap[name]; !ok {
+			log.Infof("Module[%s] is added during startup and waits for requires%v", name, set.requiresOf(name))
+			m.watchStart(name)
+		}
+	}
+}
+
+// prepareModule 为模块创建运行上下文，并返回启动该模块并等待其切换状态的函数。
+// 运行上下文在调用方持有写锁时串行创建，返回的函数可以并发执行。
+func (m *Manager) prepareModule(set *moduleSet, modName string, timeout time.Duration) func() error {
+	if cancel, ok := m.cancelMap[modName]; ok {
+		cancel() // 避免异常退出的模块的协程泄露
+	}
+
+	module := m.moduleMap[modName]
+	m.contextMap[modName], m.cancelMap[modName] = m.moduleContext(set, modName)
+	modCtx := m.contextMap[modName]
+	modStatus := module.Status()
+	if modStatus == nil {
+		log.Errorf("Unable to obtain module[%s] status.", modName)
+		return nil
+	}
+	return func() error {
 		log.Infof("Start function module[%s] by order", modName)
-		if cancel, ok := m.cancelMap[modName]; ok {
-			cancel() // 避免异常退出的模块的协程泄露
+		if err := m.emit(Event{Type: EventStarting, Module: modName}); err != nil {
+			err = errors.Wrapf(err, "Module[%s] before start hook failed", modName)
+			_ = m.emit(Event{Type: EventFailed, Module: modName, Err: err})
+			return err
 		}
 
-		m.contextMap[modName], m.cancelMap[modName] = context.WithCancel(m.ctx)
-		modStatus := m.moduleMap[modName].Status()
-		if modStatus == nil {
-			log.Errorf("Unable to obtain module[%s] status.", modName)
-			continue
+		// 声明发布对象的依赖完成发布后再启动，使模块在 Run 中可以直接获取对象
+		deadline := m.clock().After(timeout)
+		if err := m.waitProvided(modCtx, deadline); err != nil {
+			_ = m.emit(Event{Type: EventFailed, Module: modName, Err: err})
+			return err
 		}
-		go startAndServe(m.contextMap[modName], m.moduleMap[modName])
+
+		changed, runs := modStatus.Changed(), modStatus.runCount()
+		go m.runModule(modCtx, modName, module)
 		select {
-		case <-time.After(time.Second):
-			log.Fatalf("Module[%s] startup time exceeds expectations", modName)
-		case status, ok := <-modStatus.Watch():
-			if !ok {
-				log.Fatalf("Module[%s] status has not been properly initialized", modName)
+		case <-deadline:
+			err := errors.Wrapf(ErrStartTimeout, "Module[%s] startup time exceeds expectations", modName)
+			_ = m.emit(Event{Type: EventFailed, Module: modName, Err: err})
+			return err
+		case <-changed:
+			log.Infof("Module[%s] has been switched to status[%s]", modName, modStatus.Get())
+		}
+		if modStatus.runCount() == runs {
+			if modCtx.Err() != nil {
+				log.Infof("Module[%s] has been deleted during startup", modName)
+				return nil
 			}
-			log.Infof("Module[%s] has been switched to status[%s]", modName, status)
+			// 模块退出运行的错误由 runModule 随 EventFailed 事件发送
+			return errors.Wrapf(ErrStartFailed, "Module[%s] stopped before switching to running", modName)
 		}
+		_ = m.emit(Event{Type: EventStarted, Module: modName})
+		return nil
 	}
+}
 
-	select {
-	case <-m.ctx.Done():
-		log.Infof("Module manager exit by context")
+// startFailed 处理模块启动失败，FailFast 时终止进程
+func (m *Manager) startFailed(err error) {
+	if m.options().FailFast {
+		log.Fatalf("Start function module failed. %+v", err)
 		return
 	}
+	log.Errorf("Start function module failed. %+v", err)
 }
 
-func startAndServe(ctx context.Context, module Module) {
+// options 返回管理器选项，未设置时使用默认选项
+func (m *Manager) options() *Options {
+	if m.Options == nil {
+		return DefaultOptions()
+	}
+	return m.Options
+}
+
+// runModule 运行模块直至其退出，并发送模块退出或异常的生命周期事件
+func (m *Manager) runModule(ctx context.Context, modName string, module Module) {
 	defer func() {
 		if err := recover(); err != nil {
-			log.Fatalf("Module[%s] throws a panic during running. %+v", module.Name(), err)
+			panicErr := errors.Errorf("Module[%s] throws a panic during running. %+v", modName, err)
+			_ = m.emit(Event{Type: EventFailed, Module: modName, Err: panicErr})
+			if m.options().FailFast {
+				log.Fatalf("Module[%s] throws a panic during running. %+v", modName, err)
+				return
+			}
+			log.Errorf("%+v", panicErr)
+			if modStatus := module.Status(); modStatus != nil {
+				modStatus.Set(StatusStopped)
+			}
+			m.values.remove(modName)
+			_ = m.emit(Event{Type: EventStopped, Module: modName})
 		}
 	}()
 	time.Sleep(time.Millisecond)
 	module.Run(ctx)
-}
 
-// AddModule 将新模块添加到管理器的模块映射中，使用锁确保线程安全。
-func (m *Manager) AddModule(name string, module Module) {
-	m.mapLock.Lock()
-	defer m.mapLock.Unlock()
-	m.moduleMap[name] = module
+	var runErr error
+	if reporter, ok := module.(ErrorReporter); ok {
+		runErr = reporter.Err()
+	}
+	if runErr == nil && ctx.Err() == nil {
+		runErr = errors.Errorf("Module[%s] exited without being notified", modName)
+	}
+	if runErr != nil {
+		_ = m.emit(Event{Type: EventFailed, Module: modName, Err: runErr})
+	}
+	m.values.remove(modName)
+	_ = m.emit(Event{Type: EventStopped, Module: modName})
 }
 
-// DelModule 从管理器的模块映射中删除模块，使用锁确保线程安全。
-func (m *Manager) DelModule(name string) {
-	m.mapLock.Lock()
-	defer m.mapLock.Unlock()
-	delete(m.moduleMap, name)
+// Started 返回管理器是否已经完成全部模块的启动
+func (m *Manager) Started() bool {
+	return m.started.Load()
 }
 
-// Done 等待所有运行中的模块优雅地退出，使用等待组同步它们的完成。它返回一个信号所有模块退出的通道。
+// Done 按依赖逆序通知运行中的模块优雅地退出：依赖方先于被依赖方退出，同一批次中的模块同时退出。
+// 全部模块退出或超过 ShutdownTimeout 后调用 stop 结束管理器的上下文（同时取消尚未退出的模块），并通过返回的通道发出信号。
 func (m *Manager) Done(stop context.CancelFunc) <-chan struct{} {
+	m.mapLock.RLock()
+	waves := m.stopWaves()
+	m.mapLock.RUnlock()
+
 	stopCount := 0
-	doneChan := make(chan struct{})
-	wg := sync.WaitGroup{}
-	for _, mod := range m.moduleMap {
-		modStatus := mod.Status()
-		if modStatus == nil {
-			log.Errorf("Unable to obtain module[%s] status.", mod.Name())
-			continue
-		}
-		if modStatus.Get() == StatusRunning {
-			log.Infof("Module[%s] is currently running. Notify it to gracefully exit", mod.Name())
-			stopCount += 1
-		} else {
-			continue
-		}
-		wg.Add(1)
-		go func(modName string) {
-			<-modStatus.Watch()
-			log.Warnf("Module[%s] has gracefully exited", modName)
-			wg.Done()
-		}(mod.Name())
+	for _, wave := range waves {
+		stopCount += len(wave)
 	}
-
 	log.Infof("Waiting for a total of %d modules to gracefully exit", stopCount)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if timeout := m.options().ShutdownTimeout; timeout > 0 {
+		go func() {
+			select {
+			case <-m.clock().After(timeout):
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	doneChan := make(chan struct{})
 	go func() {
-		wg.Wait()
+		defer cancel()
+		for _, wave := range waves {
+			if !m.stopWave(ctx, wave) {
+				log.Errorf("Modules graceful exit exceeds %s, cancel the remaining modules", m.options().ShutdownTimeout)
+				break
+			}
+		}
+		stop()
 		doneChan <- struct{}{}
 	}()
-	stop()
 	return doneChan
 }
 
-// checkAndSort 检查模块的依赖关系并按照启动顺序进行排序。如果存在循环依赖，则返回错误。
+// stopWave 通知同一批次中的模块退出并等待它们全部停止运行，ctx 结束前未全部停止时返回 false
+func (m *Manager) stopWave(ctx context.Context, wave []stopTarget) bool {
+	wg := sync.WaitGroup{}
+	stopped := atomic.Bool{}
+	stopped.Store(true)
+	for _, target := range wave {
+		log.Infof("Module[%s] is currently running. Notify it to gracefully exit", target.name)
+		_ = m.emit(Event{Type: EventStopping, Module: target.name})
+		target.cancel()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := waitStopped(ctx, target.status); err != nil {
+				log.Errorf("Module[%s] has not stopped before shutdown deadline", target.name)
+				stopped.Store(false)
+				return
+			}
+			log.Warnf("Module[%s] has gracefully exited", target.name)
+		}()
+	}
+	wg.Wait()
+	return stopped.Load()
+}
+
+// stopTarget 待停止的运行中模块
+type stopTarget struct {
+	name   string
+	status *ModuleStatus
+	cancel context.CancelFunc
+}
+
+// stopWaves 按启动批次的逆序返回运行中的模块，不在启动批次中的运行模块最先停止，调用方需持有锁
+func (m *Manager) stopWaves() [][]stopTarget {
+	names := make([]string, 0, len(m.moduleMap))
+	for name := range m.moduleMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	waves, err := m.checkAndWave()
+	if err != nil {
+		waves = [][]string{names}
+	}
+	covered := make(map[string]bool, len(names))
+	for _, wave := range waves {
+		for _, name := range wave {
+			covered[name] = true
+		}
+	}
+	leftover := make([]string, 0)
+	for _, name := range names {
+		if !covered[name] {
+			leftover = append(leftover, name)
+		}
+	}
+
+	result := make([][]stopTarget, 0, len(waves)+1)
+	for i := len(waves); i >= 0; i-- {
+		wave := leftover
+		if i < len(waves) {
+			wave = waves[i]
+		}
+		targets := make([]stopTarget, 0, len(wave))
+		for _, name := range wave {
+			modStatus := m.moduleMap[name].Status()
+			if modStatus == nil {
+				log.Errorf("Unable to obtain module[%s] status.", name)
+				continue
+			}
+			cancel, ok := m.cancelMap[name]
+			if !ok || modStatus.Get() != StatusRunning {
+				continue
+			}
+			targets = append(targets, stopTarget{name: name, status: modStatus, cancel: cancel})
+		}
+		if len(targets) != 0 {
+			result = append(result, targets)
+		}
+	}
+	return result
+}
+
+// checkAndSort 检查模块的依赖关系并按照启动顺序进行排序。
+// 依赖缺失、自依赖与循环依赖分别返回 ErrMissingDependency、ErrSelfDependency 与 ErrCyclicDependency。
 func (m *Manager) checkAndSort() ([]string, error) {
-	queue := make(chan string, len(m.moduleMap))
+	waves, err := m.checkAndWave()
+	if err != nil {
+		return nil, err
+	}
+	order := make([]string, 0, len(m.moduleMap)) // 节点启动顺序
+	for _, wave := range waves {
+		order = append(order, wave...)
+	}
+	return order, nil
+}
+
+// checkAndWave 检查模块的依赖关系并将模块划分为启动批次。
+// 每个批次中的模块仅依赖之前批次中的模块，批次内按名称排序以保证启动顺序可复现。
+func (m *Manager) checkAndWave() ([][]string, error) {
+	return m.checkAndWaveOf(m.modules())
+}
+
+// checkAndWaveOf 使用已计算的启用模块集合检查模块的依赖关系并划分启动批次，调用方需持有锁
+func (m *Manager) checkAndWaveOf(set *moduleSet) ([][]string, error) {
+	if err := m.checkRequires(set); err != nil {
+		return nil, err
+	}
+
+	wave := make([]string, 0)
 	depNum := make(map[string]int)      // 节点对外依赖的数量
 	depMap := make(map[string][]string) // 节点被外部依赖的列表
-	for name, module := range m.moduleMap {
-		depNum[name] = len(module.Requires())
+	for _, name := range set.enabledModules() {
+		after := set.startAfter(name)
+		depNum[name] = len(after)
 		if depNum[name] == 0 {
-			queue <- name
+			wave = append(wave, name)
 		}
-		for _, depMod := range module.Requires() {
+		for _, depMod := range after {
 			depMap[depMod] = append(depMap[depMod], name)
 		}
 	}
 
-	order := make([]string, 0) // 节点启动顺序
-	for len(queue) != 0 {
-		name := <-queue
-		order = append(order, name)
+	waves := make([][]string, 0) // 节点启动批次
+	for len(wave) != 0 {
+		sort.Strings(wave)
+		waves = append(waves, wave)
 
-		for _, mod := range depMap[name] {
-			depNum[mod] -= 1
-			if depNum[mod] <= 0 {
-				queue <- mod
+		next := make([]string, 0)
+		for _, name := range wave {
+			for _, mod := range depMap[name] {
+				depNum[mod] -= 1
+				if depNum[mod] == 0 {
+					next = append(next, mod)
+				}
 			}
 		}
+		wave = next
 	}
 
 	abnormal := make([]string, 0)
@@ -174,7 +427,88 @@ func (m *Manager) checkAndSort() ([]string, error) {
 		}
 	}
 	if len(abnormal) != 0 {
-		return nil, errors.Errorf("Module may have cyclic dependencies in [%v]", abnormal)
+		sort.Strings(abnormal)
+		cycle := m.findCycle(set, abnormal)
+		if cycle == nil {
+			cycle = abnormal
+		}
+		return nil, errors.Wrapf(ErrCyclicDependency, "Module has cyclic dependencies [%s]", strings.Join(cycle, " -> "))
 	}
-	return order, nil
+	return waves, nil
+}
+
+// checkRequires 检查当前角色均有所属的模块，且每个启用模块的依赖均已添加、未被禁用且不依赖自身
+func (m *Manager) checkRequires(set *moduleSet) error {
+	errs := make([]error, 0)
+	if err := m.checkRoles(); err != nil {
+		errs = append(errs, err)
+	}
+	for _, name := range set.enabledModules() {
+		for _, depMod := range m.moduleMap[name].Requires() {
+			if group, ok := strings.CutPrefix(depMod, GroupPrefix); ok {
+				if len(m.groupMembers(group, name, set.enabled)) == 0 {
+					errs = append(errs, errors.Wrapf(ErrMissingDependency, "Module[%s] requires group[%s] which has no enabled module", name, group))
+				}
+				continue
+			}
+			if depMod == name {
+				errs = append(errs, errors.Wrapf(ErrSelfDependency, "Module[%s] requires itself", name))
+				continue
+			}
+			if _, ok := m.moduleMap[depMod]; !ok {
+				errs = append(errs, errors.Wrapf(ErrMissingDependency, "Module[%s] requires module[%s] which has not been added", name, depMod))
+				continue
+			}
+			if !set.enabled(depMod) {
+				errs = append(errs, errors.Wrapf(ErrModuleDisabled, "Module[%s] requires module[%s] which has been disabled", name, depMod))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// findCycle 从给定的模块出发深度优先查找一条依赖环路，返回首尾相同的环路路径，不存在环路时返回 nil
+func (m *Manager) findCycle(set *moduleSet, names []string) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	path := make([]string, 0)
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		state[name] = visiting
+		path = append(path, name)
+		for _, depMod := range set.startAfter(name) {
+			if _, ok := m.moduleMap[depMod]; !ok {
+				continue
+			}
+			switch state[depMod] {
+			case visiting:
+				for i := range path {
+					if path[i] == depMod {
+						return append(append([]string{}, path[i:]...), depMod)
+					}
+				}
+			case unvisited:
+				if cycle := visit(depMod); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	for _, name := range names {
+		if state[name] == unvisited {
+			if cycle := visit(name); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
 }
@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
 )
 
 type fakeModule struct {
@@ -95,7 +98,8 @@ func TestManager_checkAndSort_cyclic(t *testing.T) {
 		moduleMap: moduleMap,
 	}
 	_, err := manager.checkAndSort()
-	assert.NotNil(t, err)
+	assert.True(t, errors.Is(err, ErrCyclicDependency))
+	assert.Contains(t, err.Error(), "A -> B -> A")
 
 	moduleMap = map[string]Module{
 		"A": &fakeModule{
@@ -113,5 +117,140 @@ func TestManager_checkAndSort_cyclic(t *testing.T) {
 	}
 	manager.moduleMap = moduleMap
 	_, err = manager.checkAndSort()
-	assert.NotNil(t, err)
+	assert.True(t, errors.Is(err, ErrCyclicDependency))
+	assert.Contains(t, err.Error(), "A -> B -> C -> A")
+}
+
+func TestManager_checkAndSort_invalid(t *testing.T) {
+	manager := &Manager{
+		moduleMap: map[string]Module{
+			"A": &fakeModule{
+				name:     "A",
+				requires: []string{"B"},
+			},
+		},
+	}
+	_, err := manager.checkAndSort()
+	assert.True(t, errors.Is(err, ErrMissingDependency))
+	assert.False(t, errors.Is(err, ErrCyclicDependency))
+
+	manager.moduleMap = map[string]Module{
+		"A": &fakeModule{
+			name:     "A",
+			requires: []string{"A"},
+		},
+	}
+	_, err = manager.checkAndSort()
+	assert.True(t, errors.Is(err, ErrSelfDependency))
+}
+
+func TestManager_checkAndWave(t *testing.T) {
+	manager := &Manager{
+		moduleMap: map[string]Module{
+			"api":    &fakeModule{name: "api", requires: []string{"db", "cache"}},
+			"worker": &fakeModule{name: "worker", requires: []string{"db"}},
+			"db":     &fakeModule{name: "db"},
+			"cache":  &fakeModule{name: "cache"},
+			"log":    &fakeModule{name: "log"},
+		},
+	}
+	waves, err := manager.checkAndWave()
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"cache", "db", "log"}, {"api", "worker"}}, waves)
+}
+
+func TestManager_StartAndServe_parallel(t *testing.T) {
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+
+	mgr := NewManager()
+	mgr.Options.StartConcurrency = 4
+	for i := range 4 {
+		name := fmt.Sprintf("mod-%d", i)
+		mod := &fakeModule{name: name, status: NewModuleStatus()}
+		mod.run = func(ctx context.Context) {
+			time.Sleep(200 * time.Millisecond)
+			mod.status.Set(StatusRunning)
+			<-ctx.Done()
+			mod.status.Set(StatusStopped)
+		}
+		mgr.AddModule(name, mod)
+	}
+
+	begin := time.Now()
+	mgr.ctx = ctx
+	mgr.startAll()
+	assert.Less(t, time.Since(begin), 600*time.Millisecond)
+	assert.True(t, mgr.started.Load())
+	<-mgr.Done(stop)
+}
+
+func TestManager_Done_order(t *testing.T) {
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+
+	mgr := NewManager()
+	db := newServingModule("db")
+	api := newServingModule("api", "db")
+	apiStopped := make(chan struct{})
+	api.run = func(ctx context.Context) {
+		api.status.Set(StatusRunning)
+		<-ctx.Done()
+		time.Sleep(20 * time.Millisecond)
+		close(apiStopped)
+		api.status.Set(StatusStopped)
+	}
+	dbCancelled := make(chan bool, 1)
+	db.run = func(ctx context.Context) {
+		db.status.Set(StatusRunning)
+		<-ctx.Done()
+		select {
+		case <-apiStopped:
+			dbCancelled <- true
+		default:
+			dbCancelled <- false
+		}
+		db.status.Set(StatusStopped)
+	}
+	assert.Nil(t, mgr.AddModule("db", db))
+	assert.Nil(t, mgr.AddModule("api", api))
+	go mgr.StartAndServe(ctx)
+	assert.Eventually(t, mgr.started.Load, time.Second, 5*time.Millisecond)
+
+	<-mgr.Done(stop)
+	// 依赖方完全退出后才通知被依赖方退出，并在全部模块退出后结束管理器的上下文
+	assert.True(t, <-dbCancelled)
+	assert.NotNil(t, ctx.Err())
+}
+
+func TestManager_Done_timeout(t *testing.T) {
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+
+	mgr := NewManager()
+	mgr.Options.ShutdownTimeout = 50 * time.Millisecond
+	db := newServingModule("db")
+	stuck := newServingModule("stuck", "db")
+	release := make(chan struct{})
+	defer close(release)
+	stuck.run = func(ctx context.Context) {
+		stuck.status.Set(StatusRunning)
+		<-release
+	}
+	assert.Nil(t, mgr.AddModule("db", db))
+	assert.Nil(t, mgr.AddModule("stuck", stuck))
+	go mgr.StartAndServe(ctx)
+	assert.Eventually(t, mgr.started.Load, time.Second, 5*time.Millisecond)
+
+	select {
+	case <-mgr.Done(stop):
+	case <-time.After(time.Second):
+		t.Fatal("Done does not respect ShutdownTimeout")
+	}
+	// 超时后结束管理器的上下文，尚未通知的被依赖方随之退出
+	assert.NotNil(t, ctx.Err())
+	assert.Eventually(t, func() bool {
+		return db.status.Get() == StatusStopped
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, StatusRunning, stuck.status.Get())
 }
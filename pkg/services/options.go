@@ -0,0 +1,27 @@
+package services
+
+import "time"
+
+// Options 服务管理器的可定制选项
+type Options struct {
+	StartConcurrency int           // 同一批次中并发启动模块的最大数量，不大于 0 时表示不限制
+	StartTimeout     time.Duration // 单个模块从启动到切换状态的最长等待时间
+	StopTimeout      time.Duration // 动态删除模块时等待其停止运行的最长时间
+	ShutdownTimeout  time.Duration // 管理器退出时等待全部模块停止运行的最长时间，不大于 0 时不限制
+	DisabledGroups   []string      // 被禁用的模块分组，分组中的模块不会被启动
+	Roles            []string      // 管理器的运行角色，非空时仅启动属于这些角色的模块及其依赖
+	FailFast         bool          // 模块启动失败或运行中抛出 panic 时是否终止进程，否则仅发送 EventFailed 事件
+	Clock            Clock         // 管理器使用的时钟，为空时使用系统时钟
+}
+
+// DefaultOptions 返回默认的服务管理器选项
+func DefaultOptions() *Options {
+	return &Options{
+		StartConcurrency: 0,
+		StartTimeout:     time.Second,
+		StopTimeout:      5 * time.Second,
+		ShutdownTimeout:  30 * time.Second,
+		FailFast:         true,
+		Clock:            SystemClock{},
+	}
+}
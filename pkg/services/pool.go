@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wolfbolin/bolbox/pkg/configs"
+	"github.com/wolfbolin/bolbox/pkg/errors"
+	"github.com/wolfbolin/bolbox/pkg/log"
+	"github.com/wolfbolin/bolbox/pkg/metrics"
+)
+
+// PoolStats 工作池的运行统计
+type PoolStats struct {
+	Workers   int    // 当前的工作协程数量
+	Active    int    // 正在处理任务的协程数量
+	Queued    int    // 队列中等待处理的任务数量
+	Capacity  int    // 队列容量
+	Submitted uint64 // 已提交的任务数量
+	Completed uint64 // 已处理完成的任务数量，包括失败的任务
+	Failed    uint64 // 返回错误或抛出 panic 的任务数量
+	Panics    uint64 // 抛出 panic 的任务数量
+	Rejected  uint64 // 因队列已满或工作池关闭而被拒绝的任务数量
+	Dropped   uint64 // 排空超时后被丢弃的任务数量
+}
+
+// WorkerPool 由固定数量的协程处理有界队列中任务的工作池，可以作为模块由管理器启动与停止。
+// 队列已满时 Submit 阻塞以形成背压；上下文结束后工作池不再接受新的任务，并在处理完队列中的任务后停止运行。
+type WorkerPool[T any] struct {
+	name     string
+	requires []string
+	status   *ModuleStatus
+	handle   func(ctx context.Context, task T) error
+
+	DrainTimeout time.Duration           // 上下文结束后排空队列的最长时间，不大于 0 时等待全部任务处理完成
+	OnError      func(task T, err error) // 任务返回错误或抛出 panic 时调用，为空时仅记录日志
+
+	submitLock sync.RWMutex
+	queue      chan T
+	closing    chan struct{}
+	closed     bool
+
+	lock    sync.Mutex
+	size    int
+	stops   []chan struct{}
+	taskCtx context.Context
+	workers sync.WaitGroup
+
+	active    atomic.Int64
+	submitted atomic.Uint64
+	completed atomic.Uint64
+	failed    atomic.Uint64
+	panics    atomic.Uint64
+	rejected  atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+var _ Module = (*WorkerPool[any])(nil)
+
+// NewWorkerPool 创建一个队列容量为 queueSize、并发数量为 workers 的工作池，模块运行前提交的任务在启动后处理
+func NewWorkerPool[T any](name string, requires []string, queueSize, workers int, handle func(ctx context.Context, task T) error) *WorkerPool[T] {
+	return &WorkerPool[T]{
+		name:     name,
+		requires: requires,
+		status:   NewModuleStatus(),
+		handle:   handle,
+		queue:    make(chan T, max(queueSize, 0)),
+		closing:  make(chan struct{}),
+		size:     max(workers, 1),
+	}
+}
+
+// Name 返回模块名称
+func (p *WorkerPool[T]) Name() string {
+	return p.name
+}
+
+// Status 返回模块状态
+func (p *WorkerPool[T]) Status() *ModuleStatus {
+	return p.status
+}
+
+// Requires 返回模块依赖列表
+func (p *WorkerPool[T]) Requires() []string {
+	return p.requires
+}
+
+// Submit 提交任务，队列已满时阻塞直至队列有空位、ctx 结束或工作池关闭
+func (p *WorkerPool[T]) Submit(ctx context.Context, task T) error {
+	p.submitLock.RLock()
+	defer p.submitLock.RUnlock()
+	if p.closed {
+		p.rejected.Add(1)
+		return errors.Wrapf(ErrPoolClosed, "Worker pool[%s] is closed", p.name)
+	}
+	select {
+	case p.queue <- task:
+		p.submitted.Add(1)
+		return nil
+	case <-ctx.Done():
+		p.rejected.Add(1)
+		return errors.WithStack(ctx.Err())
+	case <-p.closing:
+		p.rejected.Add(1)
+		return errors.Wrapf(ErrPoolClosed, "Worker pool[%s] is closed", p.name)
+	}
+}
+
+// TrySubmit 以非阻塞方式提交任务，队列已满时返回 ErrQueueFull
+func (p *WorkerPool[T]) TrySubmit(task T) error {
+	p.submitLock.RLock()
+	defer p.submitLock.RUnlock()
+	if p.closed {
+		p.rejected.Add(1)
+		return errors.Wrapf(ErrPoolClosed, "Worker pool[%s] is closed", p.name)
+	}
+	select {
+	case p.queue <- task:
+		p.submitted.Add(1)
+		return nil
+	default:
+		p.rejected.Add(1)
+		return errors.Wrapf(ErrQueueFull, "Worker pool[%s] queue is full", p.name)
+	}
+}
+
+// Resize 调整工作协程的数量，缩减时正在处理任务的协程在处理完当前任务后退出
+func (p *WorkerPool[T]) Resize(workers int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.size = max(workers, 1)
+	if p.taskCtx != nil {
+		p.scale()
+	}
+	log.Infof("Worker pool[%s] concurrency is resized to %d", p.name, p.size)
+}
+
+// BindConcurrency 在整数类型的配置变更时调整工作协程的数量
+func (p *WorkerPool[T]) BindConcurrency(conf *configs.Config) {
+	conf.OnChange(func(value any) {
+		rv := reflect.ValueOf(value)
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			p.Resize(int(rv.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			p.Resize(int(rv.Uint()))
+		default:
+			log.Errorf("Worker pool[%s] concurrency config has unsupported type %T", p.name, value)
+		}
+	})
+}
+
+// Stats 返回工作池的运行统计
+func (p *WorkerPool[T]) Stats() PoolStats {
+	p.lock.Lock()
+	workers := len(p.stops)
+	p.lock.Unlock()
+	p.submitLock.RLock()
+	queued, capacity := len(p.queue), cap(p.queue)
+	p.submitLock.RUnlock()
+	return PoolStats{
+		Workers:   workers,
+		Active:    int(p.active.Load()),
+		Queued:    queued,
+		Capacity:  capacity,
+		Submitted: p.submitted.Load(),
+		Completed: p.completed.Load(),
+		Failed:    p.failed.Load(),
+		Panics:    p.panics.Load(),
+		Rejected:  p.rejected.Load(),
+		Dropped:   p.dropped.Load(),
+	}
+}
+
+// RegisterMetrics 将工作池的运行统计注册到指标注册表中，指标以 pool 标签区分不同的工作池
+func (p *WorkerPool[T]) RegisterMetrics(registry *metrics.Registry) {
+	poolLabel := []string{"pool"}
+	collect := func(value func(stats *PoolStats) float64) func(emit func(value float64, labelValues ...string)) {
+		return func(emit func(value float64, labelValues ...string)) {
+			stats := p.Stats()
+			emit(value(&stats), p.name)
+		}
+	}
+	registry.GaugeFunc("bolbox_pool_workers", "Number of worker goroutines.", poolLabel,
+		collect(func(stats *PoolStats) float64 { return float64(stats.Workers) }))
+	registry.GaugeFunc("bolbox_pool_active_workers", "Number of workers processing tasks.", poolLabel,
+		collect(func(stats *PoolStats) float64 { return float64(stats.Active) }))
+	registry.GaugeFunc("bolbox_pool_queued_tasks", "Number of tasks waiting in the queue.", poolLabel,
+		collect(func(stats *PoolStats) float64 { return float64(stats.Queued) }))
+	registry.CounterFunc("bolbox_pool_completed_total", "Total number of completed tasks.", poolLabel,
+		collect(func(stats *PoolStats) float64 { return float64(stats.Completed) }))
+	registry.CounterFunc("bolbox_pool_failed_total", "Total number of failed tasks.", poolLabel,
+		collect(func(stats *PoolStats) float64 { return float64(stats.Failed) }))
+	registry.CounterFunc("bolbox_pool_rejected_total", "Total number of rejected tasks.", poolLabel,
+		collect(func(stats *PoolStats) float64 { return float64(stats.Rejected) }))
+}
+
+// Run 启动工作协程处理队列中的任务，上下文结束后排空队列并等待全部工作协程退出
+func (p *WorkerPool[T]) Run(ctx context.Context) {
+	p.submitLock.Lock()
+	if p.closed {
+		p.queue, p.closing, p.closed = make(chan T, cap(p.queue)), make(chan struct{}), false
+	}
+	p.submitLock.Unlock()
+
+	taskCtx, cancelTasks := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelTasks()
+	p.lock.Lock()
+	p.taskCtx = taskCtx
+	p.scale()
+	p.lock.Unlock()
+	p.status.Set(StatusRunning)
+	log.Infof("Worker pool[%s] is running with %d workers", p.name, p.Stats().Workers)
+
+	<-ctx.Done()
+	p.lock.Lock()
+	p.taskCtx = nil // 排空期间不再调整工作协程的数量
+	p.lock.Unlock()
+	close(p.closing)
+	p.submitLock.Lock()
+	p.closed = true
+	close(p.queue)
+	p.submitLock.Unlock()
+	log.Infof("Worker pool[%s] is draining %d queued tasks", p.name, len(p.queue))
+
+	drained := make(chan struct{})
+	go func() {
+		p.workers.Wait()
+		close(drained)
+	}()
+	if p.DrainTimeout > 0 {
+		select {
+		case <-drained:
+		case <-time.After(p.DrainTimeout):
+			log.Warnf("Worker pool[%s] drain exceeds %s, cancel running tasks", p.name, p.DrainTimeout)
+			cancelTasks()
+		}
+	}
+	<-drained
+
+	p.lock.Lock()
+	p.stops = nil
+	p.lock.Unlock()
+	p.status.Set(StatusStopped)
+}
+
+// scale 按照期望的数量启动或停止工作协程，调用方需持有锁
+func (p *WorkerPool[T]) scale() {
+	for len(p.stops) < p.size {
+		stop := make(chan struct{})
+		p.stops = append(p.stops, stop)
+		p.workers.Add(1)
+		go p.work(p.taskCtx, stop)
+	}
+	for len(p.stops) > p.size {
+		close(p.stops[len(p.stops)-1])
+		p.stops = p.stops[:len(p.stops)-1]
+	}
+}
+
+// work 循环处理队列中的任务，直至被停止或队列被关闭且排空
+func (p *WorkerPool[T]) work(ctx context.Context, stop chan struct{}) {
+	defer p.workers.Done()
+	for {
+		select {
+		case <-stop:
+			return
+		case task, ok := <-p.queue:
+			if !ok {
+				return
+			}
+			if ctx.Err() != nil {
+				p.dropped.Add(1) // 排空超时后丢弃剩余的任务
+				continue
+			}
+			p.process(ctx, task)
+		}
+	}
+}
+
+// process 处理单个任务，将 panic 转换为错误
+func (p *WorkerPool[T]) process(ctx context.Context, task T) {
+	p.active.Add(1)
+	defer p.active.Add(-1)
+	defer p.completed.Add(1)
+
+	err := func() (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				p.panics.Add(1)
+				err = errors.Wrapf(ErrTaskPanic, "Worker pool[%s] task throws a panic. %+v", p.name, recovered)
+			}
+		}()
+		return p.handle(ctx, task)
+	}()
+	if err == nil {
+		return
+	}
+	p.failed.Add(1)
+	if p.OnError != nil {
+		p.OnError(task, err)
+		return
+	}
+	log.Errorf("Worker pool[%s] process task failed. %+v", p.name, err)
+}
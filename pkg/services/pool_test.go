@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wolfbolin/bolbox/pkg/configs"
+	"github.com/wolfbolin/bolbox/pkg/errors"
+)
+
+func TestWorkerPool(t *testing.T) {
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+
+	sum := atomic.Int64{}
+	failures := make(chan error, 2)
+	pool := NewWorkerPool("pool", nil, 4, 2, func(ctx context.Context, task int) error {
+		switch task {
+		case -1:
+			return errors.New("negative task")
+		case 0:
+			panic("zero task")
+		}
+		sum.Add(int64(task))
+		return nil
+	})
+	pool.OnError = func(task int, err error) {
+		failures <- err
+	}
+
+	// 模块运行前提交的任务在启动后处理
+	assert.Nil(t, pool.Submit(context.TODO(), 1))
+	changed := pool.Status().Changed()
+	go pool.Run(ctx)
+	<-changed
+	assert.Equal(t, StatusRunning, pool.Status().Get())
+
+	for _, task := range []int{2, 3, -1, 0} {
+		assert.Nil(t, pool.Submit(context.TODO(), task))
+	}
+	assert.NotNil(t, <-failures)
+	assert.NotNil(t, <-failures)
+
+	stop()
+	assert.Nil(t, waitStopped(context.TODO(), pool.Status()))
+	assert.Equal(t, int64(6), sum.Load())
+	assert.ErrorIs(t, pool.Submit(context.TODO(), 4), ErrPoolClosed)
+
+	stats := pool.Stats()
+	assert.Equal(t, uint64(5), stats.Submitted)
+	assert.Equal(t, uint64(5), stats.Completed)
+	assert.Equal(t, uint64(2), stats.Failed)
+	assert.Equal(t, uint64(1), stats.Panics)
+	assert.Equal(t, uint64(1), stats.Rejected)
+	assert.Equal(t, 0, stats.Workers)
+}
+
+func TestWorkerPoolPanicError(t *testing.T) {
+	failures := make(chan error, 1)
+	pool := NewWorkerPool("pool", nil, 1, 1, func(ctx context.Context, task string) error {
+		panic(task)
+	})
+	pool.OnError = func(task string, err error) {
+		failures <- err
+	}
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+	go pool.Run(ctx)
+
+	assert.Nil(t, pool.Submit(context.TODO(), "boom"))
+	err := <-failures
+	assert.ErrorIs(t, err, ErrTaskPanic)
+	assert.ErrorContains(t, err, "boom")
+}
+
+func TestWorkerPoolBackpressure(t *testing.T) {
+	release := make(chan struct{})
+	pool := NewWorkerPool("pool", nil, 1, 1, func(ctx context.Context, task int) error {
+		<-release
+		return nil
+	})
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+	changed := pool.Status().Changed()
+	go pool.Run(ctx)
+	<-changed
+
+	assert.Nil(t, pool.Submit(context.TODO(), 1)) // 被工作协程取走
+	assert.Eventually(t, func() bool { return pool.Stats().Active == 1 }, time.Second, time.Millisecond)
+	assert.Nil(t, pool.TrySubmit(2)) // 占满队列
+	assert.ErrorIs(t, pool.TrySubmit(3), ErrQueueFull)
+
+	timeout, cancel := context.WithTimeout(context.TODO(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, pool.Submit(timeout, 3), context.DeadlineExceeded)
+
+	// 上下文结束后排空队列中的任务
+	stop()
+	close(release)
+	assert.Nil(t, waitStopped(context.TODO(), pool.Status()))
+	assert.Equal(t, uint64(2), pool.Stats().Completed)
+	assert.Equal(t, uint64(2), pool.Stats().Rejected)
+}
+
+func TestWorkerPoolDrainTimeout(t *testing.T) {
+	pool := NewWorkerPool("pool", nil, 4, 1, func(ctx context.Context, task int) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	pool.DrainTimeout = 20 * time.Millisecond
+	pool.OnError = func(task int, err error) {}
+	for i := range 3 {
+		assert.Nil(t, pool.TrySubmit(i))
+	}
+
+	ctx, stop := context.WithCancel(context.TODO())
+	changed := pool.Status().Changed()
+	go pool.Run(ctx)
+	<-changed
+	stop()
+	assert.Nil(t, waitStopped(context.TODO(), pool.Status()))
+
+	stats := pool.Stats()
+	assert.Equal(t, uint64(1), stats.Completed)
+	assert.Equal(t, uint64(2), stats.Dropped)
+}
+
+func TestWorkerPoolResize(t *testing.T) {
+	type Config struct {
+		Workers int
+	}
+	conf := configs.NewManager(&Config{Workers: 2})
+	workers, err := conf.Conf("Workers")
+	assert.Nil(t, err)
+
+	pool := NewWorkerPool("pool", nil, 1, 2, func(ctx context.Context, task int) error {
+		return nil
+	})
+	pool.BindConcurrency(workers)
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+	changed := pool.Status().Changed()
+	go pool.Run(ctx)
+	<-changed
+	assert.Equal(t, 2, pool.Stats().Workers)
+
+	assert.Nil(t, workers.SetByString("5"))
+	assert.Eventually(t, func() bool { return pool.Stats().Workers == 5 }, time.Second, time.Millisecond)
+	pool.Resize(1)
+	assert.Equal(t, 1, pool.Stats().Workers)
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+)
+
+// scopeKey 模块运行上下文中保存模块作用域的键
+type scopeKey struct{}
+
+// moduleScope 模块运行上下文中携带的管理器与模块信息
+type moduleScope struct {
+	manager  *Manager
+	name     string
+	requires map[string]*ModuleStatus // 模块的依赖及其状态，在创建运行上下文时获取，无法获取状态的依赖为 nil
+	provides []string                 // 依赖中声明发布对象的模块
+}
+
+// Provider 可选接口，声明模块会通过 Provide 发布对象。
+// 依赖该模块的其他模块在其发布对象之后才被启动，因此可以在 Run 中切换运行状态之前直接获取对象。
+type Provider interface {
+	Provides() bool
+}
+
+// registry 模块间共享对象的注册表，对象以发布模块的名称为键，并记录发布对象的模块
+type registry struct {
+	lock    sync.Mutex
+	values  map[string]registryEntry
+	changed chan struct{}
+}
+
+// registryEntry 注册表中的对象及其发布者，通过 Manager.Provide 发布的对象没有发布者
+type registryEntry struct {
+	value    any
+	provider string
+}
+
+// Provide 将对象以当前模块的名称发布到管理器的注册表中，供依赖该模块的其他模块获取。
+// 对象应在模块切换为运行状态前发布，并在模块退出运行后自动移除。
+func Provide(ctx context.Context, value any) error {
+	scope, ok := ctx.Value(scopeKey{}).(*moduleScope)
+	if !ok {
+		return errors.Wrapf(ErrNotManaged, "Provide value outside of module context")
+	}
+	scope.manager.values.set(scope.name, value, scope.name)
+	return nil
+}
+
+// Resolve 从管理器的注册表中获取指定模块发布的对象并转换为类型 T。
+// 若该模块是当前模块的依赖且尚未发布对象，将等待其发布直至上下文结束，
+// 因此依赖方在 Run 中总能获取到被依赖方发布的对象；被依赖方在发布前退出运行时返回 ErrNotProvided。
+// Resolve 不持有管理器的锁，可以在模块切换为运行状态之前调用。
+func Resolve[T any](ctx context.Context, name string) (T, error) {
+	var zero T
+	scope, ok := ctx.Value(scopeKey{}).(*moduleScope)
+	if !ok {
+		return zero, errors.Wrapf(ErrNotManaged, "Resolve value[%s] outside of module context", name)
+	}
+
+	provider, wait := scope.requires[name]
+	for {
+		var stopped <-chan struct{}
+		if provider != nil {
+			stopped = provider.Changed()
+		}
+		value, changed, ok := scope.manager.values.get(name)
+		if ok {
+			typed, ok := value.(T)
+			if !ok {
+				return zero, errors.Wrapf(ErrValueType, "Value[%s] is %T rather than %s", name, value, reflect.TypeFor[T]())
+			}
+			return typed, nil
+		}
+		if !wait {
+			return zero, errors.Wrapf(ErrNotProvided, "Value[%s] has not been provided", name)
+		}
+		if provider != nil && provider.Get() != StatusRunning {
+			return zero, errors.Wrapf(ErrNotProvided, "Module[%s] stopped before providing value", name)
+		}
+
+		select {
+		case <-changed:
+		case <-stopped:
+		case <-ctx.Done():
+			return zero, errors.Wrapf(ctx.Err(), "Wait for value[%s] to be provided failed", name)
+		}
+	}
+}
+
+// Provide 以指定名称向注册表发布对象，可用于发布不属于任何模块的共享对象。
+// 对象不属于任何模块，同名模块退出运行时不会移除该对象
+func (m *Manager) Provide(name string, value any) {
+	m.values.set(name, value, "")
+}
+
+// Value 从注册表中获取指定名称的对象
+func (m *Manager) Value(name string) (any, bool) {
+	value, _, ok := m.values.get(name)
+	return value, ok
+}
+
+// moduleContext 创建模块的运行上下文，上下文中携带模块作用域用于发布与获取共享对象，调用方需持有锁
+func (m *Manager) moduleContext(set *moduleSet, modName string) (context.Context, context.CancelFunc) {
+	requires := set.requiresOf(modName)
+	scope := &moduleScope{
+		manager:  m,
+		name:     modName,
+		requires: make(map[string]*ModuleStatus, len(requires)),
+		provides: make([]string, 0),
+	}
+	for _, depMod := range requires {
+		dep, ok := m.moduleMap[depMod]
+		if !ok {
+			scope.requires[depMod] = nil
+			continue
+		}
+		scope.requires[depMod] = dep.Status()
+		if provider, ok := dep.(Provider); ok && provider.Provides() {
+			scope.provides = append(scope.provides, depMod)
+		}
+	}
+	return context.WithCancel(context.WithValue(m.ctx, scopeKey{}, scope))
+}
+
+// waitProvided 等待依赖中声明发布对象的模块完成发布，依赖在发布前退出运行、deadline 到达或上下文结束时返回错误
+func (m *Manager) waitProvided(ctx context.Context, deadline <-chan time.Time) error {
+	scope, ok := ctx.Value(scopeKey{}).(*moduleScope)
+	if !ok {
+		return nil
+	}
+	for _, name := range scope.provides {
+		provider := scope.requires[name]
+		for {
+			var stopped <-chan struct{}
+			if provider != nil {
+				stopped = provider.Changed()
+			}
+			_, changed, ok := m.values.get(name)
+			if ok {
+				break
+			}
+			if provider != nil && provider.Get() != StatusRunning {
+				return errors.Wrapf(ErrNotProvided, "Module[%s] stopped before providing value", name)
+			}
+
+			select {
+			case <-changed:
+			case <-stopped:
+			case <-deadline:
+				return errors.Wrapf(ErrStartTimeout, "Module[%s] waits for value of module[%s] exceeding expectations", scope.name, name)
+			case <-ctx.Done():
+				return errors.Wrapf(ctx.Err(), "Module[%s] waits for value of module[%s] failed", scope.name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// get 获取对象，同时返回一个在注册表下一次变更时关闭的通道
+func (r *registry) get(name string) (any, <-chan struct{}, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.changed == nil {
+		r.changed = make(chan struct{})
+	}
+	entry, ok := r.values[name]
+	return entry.value, r.changed, ok
+}
+
+func (r *registry) set(name string, value any, provider string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.values == nil {
+		r.values = make(map[string]registryEntry)
+	}
+	r.values[name] = registryEntry{value: value, provider: provider}
+	r.notify()
+}
+
+// remove 移除模块发布的对象，由其他发布者以相同名称发布的对象被保留
+func (r *registry) remove(provider string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, ok := r.values[provider]
+	if !ok || entry.provider != provider {
+		return
+	}
+	delete(r.values, provider)
+	r.notify()
+}
+
+func (r *registry) notify() {
+	if r.changed != nil {
+		close(r.changed)
+		r.changed = nil
+	}
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+)
+
+type fakePool struct {
+	dsn string
+}
+
+func TestResolve(t *testing.T) {
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+
+	mgr := NewManager()
+	mgr.Provide("config", "shared")
+	assert.Nil(t, mgr.AddModule("db", NewFuncModule("db", nil, func(ctx context.Context) error {
+		// 延迟发布对象，依赖方仍然能够获取
+		time.Sleep(20 * time.Millisecond)
+		assert.Nil(t, Provide(ctx, &fakePool{dsn: "mysql://"}))
+		<-ctx.Done()
+		return nil
+	})))
+
+	resolved := make(chan *fakePool, 1)
+	assert.Nil(t, mgr.AddModule("api", NewFuncModule("api", []string{"db"}, func(ctx context.Context) error {
+		pool, err := Resolve[*fakePool](ctx, "db")
+		assert.Nil(t, err)
+		resolved <- pool
+
+		config, err := Resolve[string](ctx, "config")
+		assert.Nil(t, err)
+		assert.Equal(t, "shared", config)
+
+		_, err = Resolve[int](ctx, "config")
+		assert.True(t, errors.Is(err, ErrValueType))
+		_, err = Resolve[string](ctx, "cache")
+		assert.True(t, errors.Is(err, ErrNotProvided))
+		<-ctx.Done()
+		return nil
+	})))
+	mgr.ctx = ctx
+	mgr.startAll()
+	assert.Equal(t, "mysql://", (<-resolved).dsn)
+
+	_, err := Resolve[*fakePool](context.TODO(), "db")
+	assert.True(t, errors.Is(err, ErrNotManaged))
+
+	assert.Nil(t, mgr.DelModule("api"))
+	assert.Nil(t, mgr.DelModule("db"))
+	assert.Eventually(t, func() bool {
+		_, ok := mgr.Value("db")
+		return !ok
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestResolve_providerStopped(t *testing.T) {
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+
+	mgr := NewManager()
+	mgr.Options.FailFast = false
+	exit := make(chan struct{})
+	assert.Nil(t, mgr.AddModule("db", NewFuncModule("db", nil, func(ctx context.Context) error {
+		<-exit
+		return errors.New("connection lost")
+	})))
+	resolved := make(chan error, 1)
+	assert.Nil(t, mgr.AddModule("api", NewFuncModule("api", []string{"db"}, func(ctx context.Context) error {
+		_, err := Resolve[*fakePool](ctx, "db")
+		resolved <- err
+		<-ctx.Done()
+		return nil
+	})))
+	mgr.ctx = ctx
+	mgr.startAll()
+
+	// 被依赖方在发布对象前退出时唤醒等待的依赖方
+	close(exit)
+	select {
+	case err := <-resolved:
+		assert.True(t, errors.Is(err, ErrNotProvided))
+	case <-time.After(time.Second):
+		t.Fatal("Resolve is not woken up after the provider stopped")
+	}
+	<-mgr.Done(stop)
+}
+
+func TestProvide_external(t *testing.T) {
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+
+	mgr := NewManager()
+	assert.Nil(t, mgr.AddModule("db", NewFuncModule("db", nil, func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})))
+	mgr.ctx = ctx
+	mgr.startAll()
+
+	// 通过 Manager.Provide 发布的同名对象不随模块退出而移除
+	mgr.Provide("db", "external")
+	assert.Nil(t, mgr.DelModule("db"))
+	time.Sleep(20 * time.Millisecond)
+	value, ok := mgr.Value("db")
+	assert.True(t, ok)
+	assert.Equal(t, "external", value)
+}
+
+// providerModule 声明会发布对象的模块，在切换为运行状态之后才发布对象
+type providerModule struct {
+	*fakeModule
+}
+
+func (p providerModule) Provides() bool {
+	return true
+}
+
+func TestResolve_beforeRunning(t *testing.T) {
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+
+	mgr := NewManager()
+	mgr.Options.FailFast = false
+	db := &fakeModule{name: "db", status: NewModuleStatus()}
+	db.run = func(ctx context.Context) {
+		db.status.Set(StatusRunning)
+		time.Sleep(20 * time.Millisecond)
+		assert.Nil(t, Provide(ctx, &fakePool{dsn: "mysql://"}))
+		<-ctx.Done()
+		db.status.Set(StatusStopped)
+	}
+	assert.Nil(t, mgr.AddModule("db", providerModule{db}))
+
+	// 依赖方在切换为运行状态之前获取对象，声明发布对象的依赖在其启动前已完成发布
+	provided := make(chan bool, 1)
+	api := &fakeModule{name: "api", status: NewModuleStatus(), requires: []string{"db"}}
+	api.run = func(ctx context.Context) {
+		_, ok := mgr.Value("db")
+		provided <- ok
+		pool, err := Resolve[*fakePool](ctx, "db")
+		assert.Nil(t, err)
+		assert.Equal(t, "mysql://", pool.dsn)
+		api.status.Set(StatusRunning)
+		<-ctx.Done()
+		api.status.Set(StatusStopped)
+	}
+	assert.Nil(t, mgr.AddModule("api", api))
+	failed := make(chan error, 2)
+	mgr.OnFailure(func(event Event) error {
+		failed <- event.Err
+		return nil
+	})
+
+	mgr.ctx = ctx
+	mgr.startAll()
+	assert.True(t, <-provided)
+	assert.Equal(t, StatusRunning, api.status.Get())
+	assert.Empty(t, failed)
+	<-mgr.Done(stop)
+}
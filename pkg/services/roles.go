@@ -0,0 +1,95 @@
+package services
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+)
+
+// Roler 可选接口，声明模块所属的运行角色。
+// 管理器设置了角色后，仅启动属于这些角色的模块及其依赖闭包中的模块。
+type Roler interface {
+	Roles() []string
+}
+
+// SetRoles 设置管理器的运行角色，为空时启动全部未被禁用的模块，在下一次启动或添加模块时生效
+func (m *Manager) SetRoles(roles ...string) {
+	m.mapLock.Lock()
+	defer m.mapLock.Unlock()
+	if m.Options == nil {
+		m.Options = DefaultOptions()
+	}
+	m.Options.Roles = uniqueNames(roles)
+}
+
+// ParseRoles 将逗号分隔的角色字符串解析为角色列表，便于从配置的环境变量或命令行参数中读取角色
+func ParseRoles(value string) []string {
+	roles := make([]string, 0)
+	for _, role := range strings.Split(value, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	return uniqueNames(roles)
+}
+
+// roleClosure 返回属于当前角色的模块及其依赖闭包，未设置角色时返回 nil，调用方需持有锁
+func (m *Manager) roleClosure() map[string]bool {
+	roles := m.options().Roles
+	if len(roles) == 0 {
+		return nil
+	}
+
+	closure := make(map[string]bool)
+	queue := make([]string, 0)
+	for name, module := range m.moduleMap {
+		roler, ok := module.(Roler)
+		if !ok || !m.groupEnabled(name) {
+			continue
+		}
+		for _, role := range roler.Roles() {
+			if slices.Contains(roles, role) {
+				closure[name] = true
+				queue = append(queue, name)
+				break
+			}
+		}
+	}
+
+	for len(queue) != 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, depMod := range m.moduleMap[name].Requires() {
+			members := []string{depMod}
+			if group, ok := strings.CutPrefix(depMod, GroupPrefix); ok {
+				members = m.groupMembers(group, name, m.groupEnabled)
+			}
+			for _, member := range members {
+				if _, ok := m.moduleMap[member]; ok && !closure[member] {
+					closure[member] = true
+					queue = append(queue, member)
+				}
+			}
+		}
+	}
+	return closure
+}
+
+// checkRoles 检查当前角色是否均有所属的模块，调用方需持有锁
+func (m *Manager) checkRoles() error {
+	errs := make([]error, 0)
+	for _, role := range m.options().Roles {
+		found := false
+		for _, module := range m.moduleMap {
+			if roler, ok := module.(Roler); ok && slices.Contains(roler.Roles(), role) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, errors.Wrapf(ErrUnknownRole, "Role[%s] has no module", role))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+)
+
+type roleModule struct {
+	softModule
+	roles []string
+}
+
+func (r *roleModule) Roles() []string {
+	return r.roles
+}
+
+func newRoleModule(name string, roles []string, groups []string, requires ...string) *roleModule {
+	return &roleModule{
+		softModule: softModule{
+			fakeModule: fakeModule{name: name, requires: requires},
+			groups:     groups,
+		},
+		roles: roles,
+	}
+}
+
+func TestParseRoles(t *testing.T) {
+	assert.Equal(t, []string{"api", "worker"}, ParseRoles(" api, worker,,api "))
+	assert.Empty(t, ParseRoles(""))
+}
+
+func TestManager_roles(t *testing.T) {
+	mgr := NewManager()
+	mgr.AddModule("db", newRoleModule("db", nil, nil))
+	mgr.AddModule("cache", newRoleModule("cache", nil, []string{"storage"}))
+	mgr.AddModule("tracing", newRoleModule("tracing", nil, []string{"observe"}))
+	mgr.AddModule("api", newRoleModule("api", []string{"api"}, nil, "db", "@observe"))
+	mgr.AddModule("worker", newRoleModule("worker", []string{"worker"}, nil, "db", "cache"))
+
+	// 未设置角色时启动全部模块
+	waves, err := mgr.checkAndWave()
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"cache", "db", "tracing"}, {"api", "worker"}}, waves)
+
+	mgr.SetRoles(ParseRoles("api")...)
+	waves, err = mgr.checkAndWave()
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"db", "tracing"}, {"api"}}, waves)
+	assert.Equal(t, []string{"cache", "worker"}, mgr.disabledModules())
+
+	mgr.SetRoles("worker")
+	mgr.DisableGroups("storage")
+	_, err = mgr.checkAndWave()
+	assert.True(t, errors.Is(err, ErrModuleDisabled))
+	assert.Contains(t, err.Error(), "Module[worker] requires module[cache] which has been disabled")
+
+	mgr.SetRoles("scheduler")
+	_, err = mgr.checkAndWave()
+	assert.True(t, errors.Is(err, ErrUnknownRole))
+}
+
+// countingRoleModule 记录 Roles 被调用的次数
+type countingRoleModule struct {
+	*roleModule
+	calls *int
+}
+
+func (c countingRoleModule) Roles() []string {
+	*c.calls += 1
+	return c.roleModule.Roles()
+}
+
+func TestManager_rolesClosureOnce(t *testing.T) {
+	mgr := NewManager()
+	calls := 0
+	const count = 20
+	for i := range count {
+		name := fmt.Sprintf("mod-%02d", i)
+		requires := make([]string, 0)
+		if i > 0 {
+			requires = append(requires, fmt.Sprintf("mod-%02d", i-1))
+		}
+		mgr.AddModule(name, countingRoleModule{roleModule: newRoleModule(name, []string{"api"}, nil, requires...), calls: &calls})
+	}
+	mgr.SetRoles("api")
+
+	// 一次检查仅计算一次角色闭包，另有一次用于检查角色是否存在
+	waves, err := mgr.checkAndWave()
+	assert.Nil(t, err)
+	assert.Len(t, waves, count)
+	assert.LessOrEqual(t, calls, 2*count)
+}
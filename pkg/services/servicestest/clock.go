@@ -0,0 +1,103 @@
+package servicestest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wolfbolin/bolbox/pkg/services"
+)
+
+var _ services.Clock = (*FakeClock)(nil)
+
+// FakeClock 可控的时钟，时间仅在调用 Advance 时前进
+type FakeClock struct {
+	lock    sync.Mutex
+	now     time.Time
+	waiters []*waiter
+	changed chan struct{}
+}
+
+type waiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// NewFakeClock 创建一个以 now 为当前时间的时钟
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{
+		now:     now,
+		changed: make(chan struct{}),
+	}
+}
+
+// Now 返回时钟的当前时间
+func (c *FakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+// After 返回一个在时钟前进 d 之后收到当前时间的通道
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, &waiter{at: c.now.Add(d), ch: ch})
+	c.notify()
+	return ch
+}
+
+// Advance 使时钟前进 d，并唤醒全部到期的等待者
+func (c *FakeClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = c.now.Add(d)
+	sort.SliceStable(c.waiters, func(i, j int) bool {
+		return c.waiters[i].at.Before(c.waiters[j].at)
+	})
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = pending
+	c.notify()
+}
+
+// Waiters 返回尚未到期的等待者数量
+func (c *FakeClock) Waiters() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil 等待直至尚未到期的等待者数量不少于 n，超过真实时间 timeout 后返回 false
+func (c *FakeClock) BlockUntil(n int, timeout time.Duration) bool {
+	deadline := time.After(timeout)
+	for {
+		c.lock.Lock()
+		count, changed := len(c.waiters), c.changed
+		c.lock.Unlock()
+		if count >= n {
+			return true
+		}
+		select {
+		case <-changed:
+		case <-deadline:
+			return false
+		}
+	}
+}
+
+func (c *FakeClock) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
@@ -0,0 +1,91 @@
+package servicestest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/wolfbolin/bolbox/pkg/services"
+)
+
+var (
+	_ services.Module        = (*FaultyModule)(nil)
+	_ services.ErrorReporter = (*FaultyModule)(nil)
+)
+
+// FaultyModule 可注入故障的模块包装，用于测试管理器对模块异常的处理。
+// 被包装模块实现的可选接口（分组、角色等）不会被转发。
+type FaultyModule struct {
+	services.Module
+
+	lock       sync.Mutex
+	panicValue any
+	hang       bool
+	cancel     context.CancelFunc
+	crashErr   error
+}
+
+// Faulty 包装模块以便注入故障
+func Faulty(module services.Module) *FaultyModule {
+	return &FaultyModule{
+		Module: module,
+	}
+}
+
+// PanicOnRun 使下一次运行在调用被包装模块之前抛出 panic
+func (f *FaultyModule) PanicOnRun(value any) *FaultyModule {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.panicValue = value
+	return f
+}
+
+// HangOnRun 使下一次运行不切换模块状态，并阻塞至上下文结束，用于模拟启动超时
+func (f *FaultyModule) HangOnRun() *FaultyModule {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.hang = true
+	return f
+}
+
+// Crash 使运行中的被包装模块在未经管理器通知的情况下退出，并报告 err
+func (f *FaultyModule) Crash(err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.crashErr = err
+	if f.cancel != nil {
+		f.cancel()
+	}
+}
+
+// Run 按照注入的故障运行被包装模块
+func (f *FaultyModule) Run(ctx context.Context) {
+	f.lock.Lock()
+	panicValue, hang := f.panicValue, f.hang
+	f.panicValue, f.hang, f.crashErr = nil, false, nil
+	runCtx, cancel := context.WithCancel(ctx)
+	f.cancel = cancel
+	f.lock.Unlock()
+	defer cancel()
+
+	if panicValue != nil {
+		panic(panicValue)
+	}
+	if hang {
+		<-ctx.Done()
+		return
+	}
+	f.Module.Run(runCtx)
+}
+
+// Err 返回注入的崩溃错误，未注入时返回被包装模块报告的错误
+func (f *FaultyModule) Err() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.crashErr != nil {
+		return f.crashErr
+	}
+	if reporter, ok := f.Module.(services.ErrorReporter); ok {
+		return reporter.Err()
+	}
+	return nil
+}
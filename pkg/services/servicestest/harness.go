@@ -0,0 +1,293 @@
+package servicestest
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wolfbolin/bolbox/pkg/services"
+)
+
+// DefaultTimeout 等待模块状态、事件与退出时默认使用的真实时间超时
+const DefaultTimeout = 5 * time.Second
+
+// Harness 模块测试工具，使用虚拟时钟运行管理器并记录模块的生命周期事件。
+// 管理器的 FailFast 选项被关闭，模块的启动失败与 panic 仅产生 EventFailed 事件。
+type Harness struct {
+	t       testing.TB
+	Manager *services.Manager
+	Clock   *FakeClock
+	Timeout time.Duration // 等待模块状态、事件与退出的真实时间超时
+
+	modules  map[string]services.Module
+	baseline map[string]bool
+	cancel   context.CancelFunc
+	served   chan struct{}
+
+	lock    sync.Mutex
+	events  []services.Event
+	changed chan struct{}
+}
+
+// New 创建模块测试工具，modules 为可供启动的候选模块，测试结束时自动停止运行中的模块
+func New(t testing.TB, modules ...services.Module) *Harness {
+	t.Helper()
+	h := &Harness{
+		t:        t,
+		Manager:  services.NewManager(),
+		Clock:    NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+		Timeout:  DefaultTimeout,
+		modules:  make(map[string]services.Module, len(modules)),
+		baseline: goroutines(),
+		changed:  make(chan struct{}),
+	}
+	h.Manager.Options.FailFast = false
+	h.Manager.Options.Clock = h.Clock
+	for _, module := range modules {
+		h.modules[module.Name()] = module
+	}
+
+	record := func(event services.Event) error {
+		h.lock.Lock()
+		defer h.lock.Unlock()
+		h.events = append(h.events, event)
+		close(h.changed)
+		h.changed = make(chan struct{})
+		return nil
+	}
+	h.Manager.BeforeStart(record)
+	h.Manager.AfterStart(record)
+	h.Manager.BeforeStop(record)
+	h.Manager.AfterStop(record)
+	h.Manager.OnFailure(record)
+
+	t.Cleanup(func() {
+		if h.cancel != nil {
+			h.Stop()
+		}
+	})
+	return h
+}
+
+// Start 启动指定的模块及其传递依赖（包括依赖的分组与已添加的可选依赖），并等待启动流程结束。
+// 未指定模块时启动全部候选模块。
+func (h *Harness) Start(names ...string) {
+	h.t.Helper()
+	h.StartAsync(names...)
+	deadline := time.Now().Add(h.Timeout)
+	for !h.Manager.Started() {
+		select {
+		case <-h.served:
+			h.t.Fatalf("Module manager exited before startup finished")
+		default:
+		}
+		if time.Now().After(deadline) {
+			h.t.Fatalf("Module manager startup exceeds %s", h.Timeout)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// StartAsync 与 Start 相同，但不等待启动流程结束，用于配合虚拟时钟测试启动超时
+func (h *Harness) StartAsync(names ...string) {
+	h.t.Helper()
+	if h.cancel != nil {
+		h.t.Fatalf("Module manager has already been started")
+	}
+	if len(names) == 0 {
+		for name := range h.modules {
+			names = append(names, name)
+		}
+	}
+	for _, name := range h.closure(names) {
+		if err := h.Manager.AddModule(name, h.modules[name]); err != nil {
+			h.t.Fatalf("Add module[%s] failed. %+v", name, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel, h.served = cancel, make(chan struct{})
+	go func() {
+		defer close(h.served)
+		h.Manager.StartAndServe(ctx)
+	}()
+}
+
+// Stop 通知全部模块优雅地退出并等待管理器结束，返回模块退出的先后顺序
+func (h *Harness) Stop() []string {
+	h.t.Helper()
+	if h.cancel == nil {
+		h.t.Fatalf("Module manager has not been started")
+	}
+	h.lock.Lock()
+	from := len(h.events)
+	h.lock.Unlock()
+
+	select {
+	case <-h.Manager.Done(h.cancel):
+	case <-time.After(h.Timeout):
+		h.t.Fatalf("Modules graceful exit exceeds %s", h.Timeout)
+	}
+	select {
+	case <-h.served:
+	case <-time.After(h.Timeout):
+		h.t.Fatalf("Module manager exit exceeds %s", h.Timeout)
+	}
+	h.cancel = nil
+
+	order := make([]string, 0)
+	for _, event := range h.Events()[from:] {
+		if event.Type == services.EventStopped {
+			order = append(order, event.Module)
+		}
+	}
+	return order
+}
+
+// AssertStopOrder 停止全部模块并断言模块按给定顺序退出
+func (h *Harness) AssertStopOrder(names ...string) {
+	h.t.Helper()
+	if order := h.Stop(); !slices.Equal(order, names) {
+		h.t.Errorf("Modules exited in order %v, want %v", order, names)
+	}
+}
+
+// Events 返回已记录的全部生命周期事件
+func (h *Harness) Events() []services.Event {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return append([]services.Event{}, h.events...)
+}
+
+// Transitions 返回模块经历的生命周期事件类型序列
+func (h *Harness) Transitions(name string) []services.EventType {
+	result := make([]services.EventType, 0)
+	for _, event := range h.Events() {
+		if event.Module == name {
+			result = append(result, event.Type)
+		}
+	}
+	return result
+}
+
+// WaitEvent 等待模块产生指定类型的生命周期事件，超时后测试失败
+func (h *Harness) WaitEvent(name string, eventType services.EventType) services.Event {
+	h.t.Helper()
+	deadline := time.After(h.Timeout)
+	for seen := 0; ; {
+		h.lock.Lock()
+		events, changed := h.events[seen:], h.changed
+		seen = len(h.events)
+		h.lock.Unlock()
+		for _, event := range events {
+			if event.Module == name && event.Type == eventType {
+				return event
+			}
+		}
+		select {
+		case <-changed:
+		case <-deadline:
+			h.t.Fatalf("Module[%s] did not emit event[%s] within %s. Transitions: %v",
+				name, eventType, h.Timeout, h.Transitions(name))
+			return services.Event{}
+		}
+	}
+}
+
+// WaitStatus 等待模块切换到指定状态，超时后测试失败
+func (h *Harness) WaitStatus(name string, status services.Status) {
+	h.t.Helper()
+	module, ok := h.modules[name]
+	if !ok {
+		h.t.Fatalf("Module[%s] is not a candidate of the harness", name)
+	}
+	deadline := time.After(h.Timeout)
+	for {
+		changed := module.Status().Changed()
+		if module.Status().Get() == status {
+			return
+		}
+		select {
+		case <-changed:
+		case <-deadline:
+			h.t.Fatalf("Module[%s] did not switch to status[%s] within %s", name, status, h.Timeout)
+			return
+		}
+	}
+}
+
+// VerifyNoLeaks 断言测试工具创建之后启动的协程均已退出，协程在超时前仍未退出时测试失败
+func (h *Harness) VerifyNoLeaks() {
+	h.t.Helper()
+	deadline := time.Now().Add(h.Timeout)
+	for {
+		leaked := make([]string, 0)
+		for id, stack := range goroutineStacks() {
+			if !h.baseline[id] {
+				leaked = append(leaked, stack)
+			}
+		}
+		if len(leaked) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			h.t.Errorf("Found %d leaked goroutines:\n%s", len(leaked), strings.Join(leaked, "\n\n"))
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// closure 返回指定模块及其传递依赖的名称，按名称排序
+func (h *Harness) closure(names []string) []string {
+	visited := make(map[string]bool)
+	pending := append([]string{}, names...)
+	for len(pending) != 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if visited[name] {
+			continue
+		}
+		module, ok := h.modules[name]
+		if !ok {
+			h.t.Fatalf("Module[%s] is not a candidate of the harness", name)
+		}
+		visited[name] = true
+
+		for _, require := range module.Requires() {
+			if group, ok := strings.CutPrefix(require, services.GroupPrefix); ok {
+				pending = append(pending, h.groupMembers(group)...)
+				continue
+			}
+			pending = append(pending, require)
+		}
+		if optional, ok := module.(services.OptionalRequirer); ok {
+			for _, require := range optional.OptionalRequires() {
+				if _, ok := h.modules[require]; ok {
+					pending = append(pending, require)
+				}
+			}
+		}
+	}
+
+	result := make([]string, 0, len(visited))
+	for name := range visited {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (h *Harness) groupMembers(group string) []string {
+	members := make([]string, 0)
+	for name, module := range h.modules {
+		if grouper, ok := module.(services.Grouper); ok && slices.Contains(grouper.Groups(), group) {
+			members = append(members, name)
+		}
+	}
+	return members
+}
@@ -0,0 +1,96 @@
+package servicestest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+	"github.com/wolfbolin/bolbox/pkg/services"
+)
+
+func newModule(name string, requires ...string) *services.FuncModule {
+	return services.NewFuncModule(name, requires, func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+}
+
+func TestFakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	short, long := clock.After(time.Second), clock.After(time.Minute)
+	assert.True(t, clock.BlockUntil(2, time.Second))
+
+	clock.Advance(time.Second)
+	assert.Equal(t, time.Unix(1, 0), <-short)
+	assert.Equal(t, 1, clock.Waiters())
+	select {
+	case <-long:
+		t.Fatal("Waiter fired before its deadline")
+	default:
+	}
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, time.Unix(61, 0), <-long)
+	assert.Equal(t, time.Unix(61, 0), clock.Now())
+	assert.False(t, clock.BlockUntil(1, 10*time.Millisecond))
+}
+
+func TestHarnessStartSubset(t *testing.T) {
+	h := New(t, newModule("db"), newModule("cache"), newModule("api", "db"), newModule("worker", "cache"))
+	h.Start("api")
+
+	h.WaitStatus("api", services.StatusRunning)
+	h.WaitStatus("db", services.StatusRunning)
+	assert.Empty(t, h.Transitions("worker"))
+	assert.Empty(t, h.Transitions("cache"))
+	assert.Equal(t, []services.EventType{services.EventStarting, services.EventStarted}, h.Transitions("api"))
+
+	h.AssertStopOrder("api", "db")
+	h.VerifyNoLeaks()
+}
+
+func TestHarnessStopOrder(t *testing.T) {
+	h := New(t, newModule("db"), newModule("cache", "db"), newModule("api", "db", "cache"))
+	h.Start()
+	assert.Equal(t, []string{"api", "cache", "db"}, h.Stop())
+	assert.Equal(t, []services.EventType{
+		services.EventStarting, services.EventStarted, services.EventStopping, services.EventStopped,
+	}, h.Transitions("db"))
+	h.VerifyNoLeaks()
+}
+
+func TestHarnessPanic(t *testing.T) {
+	broken := Faulty(newModule("broken")).PanicOnRun("boom")
+	h := New(t, broken)
+	h.StartAsync()
+
+	event := h.WaitEvent("broken", services.EventFailed)
+	assert.ErrorContains(t, event.Err, "boom")
+	h.WaitEvent("broken", services.EventStopped)
+}
+
+func TestHarnessStartTimeout(t *testing.T) {
+	slow := Faulty(newModule("slow")).HangOnRun()
+	h := New(t, slow)
+	h.StartAsync()
+
+	assert.True(t, h.Clock.BlockUntil(1, time.Second))
+	h.Clock.Advance(h.Manager.Options.StartTimeout)
+	event := h.WaitEvent("slow", services.EventFailed)
+	assert.ErrorIs(t, event.Err, services.ErrStartTimeout)
+}
+
+func TestHarnessCrash(t *testing.T) {
+	crashed := errors.New("connection lost")
+	worker := Faulty(newModule("worker"))
+	h := New(t, worker)
+	h.Start()
+
+	worker.Crash(crashed)
+	event := h.WaitEvent("worker", services.EventFailed)
+	assert.ErrorIs(t, event.Err, crashed)
+	h.WaitStatus("worker", services.StatusStopped)
+}
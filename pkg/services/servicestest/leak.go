@@ -0,0 +1,43 @@
+package servicestest
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+)
+
+// goroutines 返回当前全部协程的编号
+func goroutines() map[string]bool {
+	result := make(map[string]bool)
+	for id := range goroutineStacks() {
+		result[id] = true
+	}
+	return result
+}
+
+// goroutineStacks 返回当前协程之外全部协程的编号与调用栈
+func goroutineStacks() map[string]string {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	result := make(map[string]string)
+	for i, stack := range bytes.Split(buf, []byte("\n\n")) {
+		if i == 0 {
+			continue // 当前协程
+		}
+		header, _, _ := strings.Cut(string(stack), "\n")
+		fields := strings.Fields(header) // goroutine 7 [running]:
+		if len(fields) < 2 || fields[0] != "goroutine" {
+			continue
+		}
+		result[fields[1]] = string(stack)
+	}
+	return result
+}
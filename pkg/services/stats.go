@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wolfbolin/bolbox/pkg/metrics"
+)
+
+// 模块生命周期中的状态，用于统计模块在各状态中停留的时长
+const (
+	StateStarting = "starting"
+	StateRunning  = "running"
+	StateStopping = "stopping"
+	StateStopped  = "stopped"
+)
+
+// eventStates 生命周期事件对应的模块状态，EventFailed 不改变模块状态
+var eventStates = map[EventType]string{
+	EventStarting: StateStarting,
+	EventStarted:  StateRunning,
+	EventStopping: StateStopping,
+	EventStopped:  StateStopped,
+}
+
+// ModuleStats 模块的运行统计
+type ModuleStats struct {
+	Name           string
+	State          string
+	Starts         int                      // 启动次数
+	Restarts       int                      // 首次启动之后的启动次数，包括 LeaderModule 重新获得领导权后再次运行被包装模块
+	Failures       int                      // 失败次数
+	Uptime         time.Duration            // 本次进入运行状态至今的时长，未运行时为 0
+	StartupLatency time.Duration            // 最近一次从开始启动到进入运行状态的耗时
+	StateDurations map[string]time.Duration // 各状态累计停留的时长，包括当前状态
+}
+
+type moduleStats struct {
+	ModuleStats
+	stateAt   time.Time
+	startAt   time.Time
+	runningAt time.Time
+}
+
+// statsRecorder 根据生命周期事件记录模块的运行统计
+type statsRecorder struct {
+	lock       sync.Mutex
+	modules    map[string]*moduleStats
+	histograms []*metrics.Histogram
+}
+
+// Stats 返回全部模块的运行统计（按名称排序）
+func (m *Manager) Stats() []ModuleStats {
+	return m.stats.snapshot(m.clock().Now())
+}
+
+// RegisterMetrics 将模块的运行统计注册到指标注册表中，指标在采集时计算取值。
+// 重复注册到同一个注册表时复用已注册的指标，启动耗时不会被重复统计
+func (m *Manager) RegisterMetrics(registry *metrics.Registry) {
+	moduleLabel := []string{"module"}
+	collect := func(value func(stats *ModuleStats) float64) func(emit func(value float64, labelValues ...string)) {
+		return func(emit func(value float64, labelValues ...string)) {
+			for _, stats := range m.Stats() {
+				emit(value(&stats), stats.Name)
+			}
+		}
+	}
+
+	registry.GaugeFunc("bolbox_module_up", "Whether the module is running.", moduleLabel,
+		collect(func(stats *ModuleStats) float64 {
+			if stats.State == StateRunning {
+				return 1
+			}
+			return 0
+		}))
+	registry.GaugeFunc("bolbox_module_uptime_seconds", "Seconds since the module entered running state.", moduleLabel,
+		collect(func(stats *ModuleStats) float64 {
+			return stats.Uptime.Seconds()
+		}))
+	registry.CounterFunc("bolbox_module_starts_total", "Total number of module starts.", moduleLabel,
+		collect(func(stats *ModuleStats) float64 {
+			return float64(stats.Starts)
+		}))
+	registry.CounterFunc("bolbox_module_restarts_total", "Total number of module restarts.", moduleLabel,
+		collect(func(stats *ModuleStats) float64 {
+			return float64(stats.Restarts)
+		}))
+	registry.CounterFunc("bolbox_module_failures_total", "Total number of module failures.", moduleLabel,
+		collect(func(stats *ModuleStats) float64 {
+			return float64(stats.Failures)
+		}))
+	registry.GaugeFunc("bolbox_module_startup_latency_seconds", "Seconds taken by the latest module startup.", moduleLabel,
+		collect(func(stats *ModuleStats) float64 {
+			return stats.StartupLatency.Seconds()
+		}))
+	registry.CounterFunc("bolbox_module_state_seconds_total", "Total seconds the module spent in each state.",
+		[]string{"module", "state"}, func(emit func(value float64, labelValues ...string)) {
+			for _, stats := range m.Stats() {
+				for _, state := range []string{StateStarting, StateRunning, StateStopping, StateStopped} {
+					emit(stats.StateDurations[state].Seconds(), stats.Name, state)
+				}
+			}
+		})
+
+	histogram := registry.Histogram("bolbox_module_startup_seconds", "Distribution of module startup seconds.", nil, "module")
+	m.stats.lock.Lock()
+	defer m.stats.lock.Unlock()
+	if !slices.Contains(m.stats.histograms, histogram) {
+		m.stats.histograms = append(m.stats.histograms, histogram)
+	}
+}
+
+// record 根据生命周期事件更新模块的运行统计
+func (s *statsRecorder) record(event Event) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.modules == nil {
+		s.modules = make(map[string]*moduleStats)
+	}
+	stats, ok := s.modules[event.Module]
+	if !ok && event.Type != EventStarting {
+		return // 模块已被删除或从未启动
+	}
+	if !ok {
+		stats = &moduleStats{
+			ModuleStats: ModuleStats{
+				Name:           event.Module,
+				State:          StateStopped,
+				StateDurations: make(map[string]time.Duration),
+			},
+			stateAt: event.Time,
+		}
+		s.modules[event.Module] = stats
+	}
+
+	switch event.Type {
+	case EventStarting:
+		stats.Starts += 1
+		if stats.Starts > 1 {
+			stats.Restarts += 1
+		}
+		stats.startAt = event.Time
+	case EventStarted:
+		stats.StartupLatency = event.Time.Sub(stats.startAt)
+		stats.runningAt = event.Time
+		for _, histogram := range s.histograms {
+			histogram.Observe(stats.StartupLatency.Seconds(), event.Module)
+		}
+	case EventFailed:
+		stats.Failures += 1
+	}
+
+	if state, ok := eventStates[event.Type]; ok && state != stats.State {
+		stats.StateDurations[stats.State] += event.Time.Sub(stats.stateAt)
+		stats.State, stats.stateAt = state, event.Time
+	}
+}
+
+// restart 记录模块在运行期间的一次重新启动
+func (s *statsRecorder) restart(name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if stats, ok := s.modules[name]; ok {
+		stats.Restarts += 1
+	}
+}
+
+// recordRestart 记录 ctx 所属模块在运行期间的一次重新启动，ctx 不是模块的运行上下文时不做任何操作
+func recordRestart(ctx context.Context) {
+	if scope, ok := ctx.Value(scopeKey{}).(*moduleScope); ok {
+		scope.manager.stats.restart(scope.name)
+	}
+}
+
+// remove 移除模块的运行统计
+func (s *statsRecorder) remove(name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.modules, name)
+}
+
+// snapshot 返回以 now 为准的全部模块运行统计副本
+func (s *statsRecorder) snapshot(now time.Time) []ModuleStats {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	result := make([]ModuleStats, 0, len(s.modules))
+	for _, stats := range s.modules {
+		item := stats.ModuleStats
+		item.StateDurations = make(map[string]time.Duration, len(stats.StateDurations)+1)
+		for state, duration := range stats.StateDurations {
+			item.StateDurations[state] = duration
+		}
+		item.StateDurations[stats.State] += now.Sub(stats.stateAt)
+		if stats.State == StateRunning {
+			item.Uptime = now.Sub(stats.runningAt)
+		}
+		result = append(result, item)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
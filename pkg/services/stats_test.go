@@ -0,0 +1,87 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wolfbolin/bolbox/pkg/errors"
+	"github.com/wolfbolin/bolbox/pkg/metrics"
+)
+
+func TestStatsRecorder(t *testing.T) {
+	begin := time.Now()
+	at := func(ms int) time.Time {
+		return begin.Add(time.Duration(ms) * time.Millisecond)
+	}
+
+	recorder := &statsRecorder{}
+	recorder.record(Event{Type: EventStopped, Module: "ghost", Time: at(0)})
+	recorder.record(Event{Type: EventStarting, Module: "db", Time: at(0)})
+	recorder.record(Event{Type: EventStarted, Module: "db", Time: at(100)})
+	recorder.record(Event{Type: EventFailed, Module: "db", Time: at(300), Err: errors.New("crash")})
+	recorder.record(Event{Type: EventStopped, Module: "db", Time: at(300)})
+	recorder.record(Event{Type: EventStarting, Module: "db", Time: at(400)})
+	recorder.record(Event{Type: EventStarted, Module: "db", Time: at(450)})
+
+	stats := recorder.snapshot(at(1450))
+	assert.Len(t, stats, 1)
+	assert.Equal(t, "db", stats[0].Name)
+	assert.Equal(t, StateRunning, stats[0].State)
+	assert.Equal(t, 2, stats[0].Starts)
+	assert.Equal(t, 1, stats[0].Restarts)
+	assert.Equal(t, 1, stats[0].Failures)
+	assert.Equal(t, time.Second, stats[0].Uptime)
+	assert.Equal(t, 50*time.Millisecond, stats[0].StartupLatency)
+	assert.Equal(t, 150*time.Millisecond, stats[0].StateDurations[StateStarting])
+	assert.Equal(t, 1200*time.Millisecond, stats[0].StateDurations[StateRunning])
+	assert.Equal(t, 100*time.Millisecond, stats[0].StateDurations[StateStopped])
+
+	recorder.remove("db")
+	assert.Empty(t, recorder.snapshot(at(1500)))
+}
+
+func TestManager_RegisterMetrics(t *testing.T) {
+	registry := metrics.NewRegistry()
+	mgr := NewManager()
+	mgr.RegisterMetrics(registry)
+	mgr.RegisterMetrics(registry) // 重复注册不会重复统计启动耗时
+	assert.Nil(t, mgr.AddModule("db", newServingModule("db")))
+	mgr.ctx = t.Context()
+	mgr.startAll()
+
+	buffer := bytes.Buffer{}
+	assert.Nil(t, registry.WriteText(&buffer))
+	assert.Contains(t, buffer.String(), "bolbox_module_up{module=\"db\"} 1\n")
+	assert.Contains(t, buffer.String(), "bolbox_module_starts_total{module=\"db\"} 1\n")
+	assert.Contains(t, buffer.String(), "bolbox_module_startup_seconds_count{module=\"db\"} 1\n")
+	assert.Nil(t, mgr.DelModule("db"))
+}
+
+func TestManager_Stats_restarts(t *testing.T) {
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+
+	election := NewMemoryElection()
+	runs := atomic.Int32{}
+	leader := NewLeaderModule(newCountingModule("job", &runs), election.Elector("a"))
+	leader.RetryInterval = 10 * time.Millisecond
+	mgr := NewManager()
+	assert.Nil(t, mgr.AddModule("job", leader))
+	go mgr.StartAndServe(ctx)
+	assert.Eventually(t, func() bool { return runs.Load() == 1 }, time.Second, time.Millisecond)
+
+	// 重新获得领导权后再次运行被包装模块计为一次重新启动
+	election.Revoke()
+	assert.Eventually(t, func() bool { return runs.Load() == 2 }, time.Second, time.Millisecond)
+	stats := mgr.Stats()
+	assert.Len(t, stats, 1)
+	assert.Equal(t, 1, stats[0].Starts)
+	assert.Equal(t, 1, stats[0].Restarts)
+
+	<-mgr.Done(stop)
+}
@@ -17,6 +17,8 @@ type ModuleStatus struct {
 	lock     sync.Mutex
 	status   Status
 	syncChan chan Status
+	changed  chan struct{}
+	runs     uint64 // 切换为运行状态的次数
 }
 
 // NewModuleStatus 新建一个 ModuleStatus 实例
@@ -24,6 +26,7 @@ func NewModuleStatus() *ModuleStatus {
 	return &ModuleStatus{
 		status:   StatusStopped,
 		syncChan: make(chan Status),
+		changed:  make(chan struct{}),
 	}
 }
 
@@ -32,6 +35,13 @@ func (s *ModuleStatus) Set(status Status) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	s.status = status
+	if status == StatusRunning {
+		s.runs += 1
+	}
+	if s.changed != nil {
+		close(s.changed)
+		s.changed = nil
+	}
 	for {
 		select {
 		case s.syncChan <- s.status:
@@ -48,7 +58,25 @@ func (s *ModuleStatus) Get() Status {
 	return s.status
 }
 
+// runCount 返回模块切换为运行状态的次数，用于判断模块在启动期间是否曾进入运行状态
+func (s *ModuleStatus) runCount() uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.runs
+}
+
 // Watch 监听状态变化
 func (s *ModuleStatus) Watch() <-chan Status {
 	return s.syncChan
 }
+
+// Changed 返回一个在下一次状态变更时关闭的通道。
+// 与 Watch 不同，在读取通道之前发生的状态变更同样不会被错过。
+func (s *ModuleStatus) Changed() <-chan struct{} {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.changed == nil {
+		s.changed = make(chan struct{})
+	}
+	return s.changed
+}
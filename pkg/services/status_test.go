@@ -22,3 +22,19 @@ func TestStatus(t *testing.T) {
 	status := <-modStatus.Watch()
 	assert.Equal(t, StatusStopped, status)
 }
+
+func TestStatus_Changed(t *testing.T) {
+	modStatus := NewModuleStatus()
+	changed := modStatus.Changed()
+
+	// 在读取通道之前发生的状态变更不会丢失
+	modStatus.Set(StatusRunning)
+	<-changed
+	assert.Equal(t, StatusRunning, modStatus.Get())
+
+	select {
+	case <-modStatus.Changed():
+		t.Fatal("status has not been changed")
+	default:
+	}
+}